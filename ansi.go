@@ -0,0 +1,92 @@
+package secret
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ANSIMode decides how ANSI escape sequences in the input, such as the
+// color codes emitted by CI tools, are treated when matching secrets.
+type ANSIMode int
+
+const (
+	// ANSIPreserve matches the rules against the input with escape
+	// sequences removed, so a secret with color codes between its
+	// characters is still found, and keeps the sequences in the output.
+	// It is the default.
+	ANSIPreserve ANSIMode = iota
+	// ANSIStrip removes escape sequences and masks the cleaned text.
+	ANSIStrip
+	// ANSIIgnore matches the rules against the input as is.
+	ANSIIgnore
+)
+
+// ansiEscapeRegex matches CSI sequences (colors, cursor movement), OSC
+// sequences terminated by BEL or ST, character set designations and
+// two-byte escapes.
+var ansiEscapeRegex = regexp.MustCompile(`\x1b(?:\[[0-?]*[ -/]*[@-~]|\][^\x07\x1b]*(?:\x07|\x1b\\)|[()][0-9A-Za-z]|[@-Z\\-_])`)
+
+// StripANSI returns s with all ANSI escape sequences removed.
+func StripANSI(s string) string {
+	if !strings.Contains(s, "\x1b") {
+		return s
+	}
+	return ansiEscapeRegex.ReplaceAllString(s, "")
+}
+
+// maskANSI masks input, which contains escape sequences, by matching the
+// rules against the cleaned text and mapping the secrets back onto input.
+// Escape sequences inside a secret are written after its replacement so the
+// terminal state of the line is unchanged. It also returns the spans of the
+// replacements within the output.
+func maskANSI(input string, rules []Rule, opts MaskOptions) (string, []span) {
+	escapes := ansiEscapeRegex.FindAllStringIndex(input, -1)
+	if escapes == nil {
+		return maskRules(input, rules, opts)
+	}
+
+	// offsets maps each byte of the cleaned text to its position in input,
+	// with a final entry for the end of input.
+	var clean strings.Builder
+	offsets := make([]int, 0, len(input)+1)
+	last := 0
+	for _, esc := range append(escapes, []int{len(input), len(input)}) {
+		for i := last; i < esc[0]; i++ {
+			offsets = append(offsets, i)
+		}
+		clean.WriteString(input[last:esc[0]])
+		last = esc[1]
+	}
+	offsets = append(offsets, len(input))
+	text := clean.String()
+
+	spans := ruleSpans(text, rules, opts)
+	if spans == nil {
+		return input, nil
+	}
+
+	var sb strings.Builder
+	replaced := make([]span, 0, len(spans))
+	written := 0
+	for _, sp := range spans {
+		rawStart, rawEnd := offsets[sp.start], offsets[sp.end-1]+1
+		sb.WriteString(input[written:rawStart])
+		start := sb.Len()
+		if sp.keep {
+			sb.WriteString(input[rawStart:rawEnd])
+			replaced = append(replaced, span{start: start, end: sb.Len()})
+			written = rawEnd
+			continue
+		}
+		sb.WriteString(opts.ruleReplacement(sp.rule, text[sp.start:sp.end]))
+		replaced = append(replaced, span{start: start, end: sb.Len()})
+		for _, esc := range escapes {
+			if esc[0] > rawStart && esc[1] <= rawEnd {
+				sb.WriteString(input[esc[0]:esc[1]])
+			}
+		}
+		written = rawEnd
+	}
+	sb.WriteString(input[written:])
+	return sb.String(), replaced
+}
@@ -0,0 +1,80 @@
+package secret
+
+import (
+	"regexp"
+	"strings"
+)
+
+// DefaultAssignmentKeys are the key names treated as sensitive by an
+// AssignmentDetector created without keys.
+var DefaultAssignmentKeys = []string{"password", "secret", "token", "api_key", "apikey", "passwd", "pwd", "private_key"}
+
+// AssignmentDetector masks the value of key = value assignments whose key
+// names a secret, such as password=hunter2 or api_key: "abc123". It catches
+// homegrown configuration keys that no provider rule knows about. Keys match
+// case-insensitively and may carry a prefix, so DB_PASSWORD counts as a
+// password. Only the value is masked; quotes around it are kept.
+type AssignmentDetector struct {
+	re *regexp.Regexp
+}
+
+// NewAssignmentDetector returns a detector for the given key names, or for
+// DefaultAssignmentKeys when none are given.
+func NewAssignmentDetector(keys ...string) *AssignmentDetector {
+	if len(keys) == 0 {
+		keys = DefaultAssignmentKeys
+	}
+	return &AssignmentDetector{re: assignmentRegex(keys)}
+}
+
+// assignmentRegex returns a regex matching assignments to any of keys.
+func assignmentRegex(keys []string) *regexp.Regexp {
+	quoted := make([]string, len(keys))
+	for i, key := range keys {
+		quoted[i] = regexp.QuoteMeta(key)
+	}
+	// The value is double-quoted, single-quoted or bare; exactly one of the
+	// three groups takes part in a match.
+	return regexp.MustCompile(`(?i)(?:^|[^\w.-])[\w.-]*(?:` + strings.Join(quoted, "|") + `)["']?\s*(?::|=>|=)\s*(?:"([^"]+)"|'([^']+)'|([^\s"',;]+))`)
+}
+
+// Mask replaces the value of every sensitive assignment in input.
+func (d *AssignmentDetector) Mask(input string, opts MaskOptions) string {
+	return maskAssignments(input, d.re, "generic-assignment", nil, opts)
+}
+
+// maskAssignments replaces the values of the assignments matched by re,
+// built by assignmentRegex, and records them under id. When secret is not
+// nil, only the values it accepts are masked.
+func maskAssignments(input string, re *regexp.Regexp, id string, secret func(value string) bool, opts MaskOptions) string {
+	matches := re.FindAllStringSubmatchIndex(input, -1)
+	if matches == nil {
+		return input
+	}
+
+	var sb strings.Builder
+	last := 0
+	for _, m := range matches {
+		for g := 1; g <= 3; g++ {
+			start, end := m[2*g], m[2*g+1]
+			if start < 0 {
+				continue
+			}
+			value := input[start:end]
+			// Values already masked by a rule are left alone.
+			if opts.placeholder(value) || opts.allowed(value) {
+				break
+			}
+			if secret != nil && !secret(value) {
+				break
+			}
+			opts.record(id, SeverityUnknown)
+			sb.WriteString(input[last:start])
+			sb.WriteString(opts.replacement(id, value))
+			last = end
+			break
+		}
+	}
+	sb.WriteString(input[last:])
+	return sb.String()
+}
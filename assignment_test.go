@@ -0,0 +1,60 @@
+package secret_test
+
+import (
+	"secret"
+	"testing"
+)
+
+func TestAssignmentDetector(t *testing.T) {
+	tests := []struct {
+		name     string
+		detector *secret.AssignmentDetector
+		input    string
+		want     string
+	}{
+		{
+			name:  "bare value",
+			input: "login password=hunter2 ok",
+			want:  "login password=****** ok",
+		},
+		{
+			name:  "double-quoted value",
+			input: `api_key: "abc123"`,
+			want:  `api_key: "******"`,
+		},
+		{
+			name:  "single-quoted value with fat arrow",
+			input: `SECRET => 'xyz'`,
+			want:  `SECRET => '******'`,
+		},
+		{
+			name:  "prefixed key",
+			input: "DB_PASSWORD=s3cr3t USER=admin",
+			want:  "DB_PASSWORD=****** USER=admin",
+		},
+		{
+			name:  "key that only contains a sensitive word",
+			input: "passwordless=true tokens=5",
+			want:  "passwordless=true tokens=5",
+		},
+		{
+			name:     "custom keys",
+			detector: secret.NewAssignmentDetector("session_id"),
+			input:    "session_id=abc token: xyz",
+			want:     "session_id=****** token: xyz",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detector := tt.detector
+			if detector == nil {
+				detector = secret.NewAssignmentDetector()
+			}
+			opts := secret.MaskOptions{Detectors: []secret.Detector{detector}}
+			if got := secret.MaskSecretsOnStringWithOptions(tt.input, secret.BuiltinRules, opts); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package secret
+
+import (
+	"encoding/base64"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultBase64MaxLen is the longest base64 run a Base64Detector decodes
+// when MaxLen is zero.
+const DefaultBase64MaxLen = 4096
+
+// base64Regex matches runs that may be standard or URL-safe base64. The
+// minimum length is that of the shortest secret worth decoding.
+var base64Regex = regexp.MustCompile(`[A-Za-z0-9+/_-]{16,}={0,2}`)
+
+// base64Encodings are tried in order on each candidate run.
+var base64Encodings = []*base64.Encoding{
+	base64.StdEncoding,
+	base64.RawStdEncoding,
+	base64.URLEncoding,
+	base64.RawURLEncoding,
+}
+
+// Base64Detector finds secrets hidden in base64, as in Kubernetes Secret
+// manifests or Docker configs. Each plausible base64 run is decoded and, if
+// the decoded text is valid UTF-8 and contains a secret matched by Rules,
+// the whole encoded run is masked.
+type Base64Detector struct {
+	// Rules are matched against the decoded text. Nil means BuiltinRules.
+	Rules []Rule
+	// MaxLen is the longest run that is decoded; longer runs are left
+	// alone. Zero means DefaultBase64MaxLen.
+	MaxLen int
+}
+
+// Mask replaces every base64 run in input whose decoded text holds a secret.
+func (d Base64Detector) Mask(input string, opts MaskOptions) string {
+	rules := d.Rules
+	if rules == nil {
+		rules = BuiltinRules
+	}
+	maxLen := d.MaxLen
+	if maxLen <= 0 {
+		maxLen = DefaultBase64MaxLen
+	}
+
+	return base64Regex.ReplaceAllStringFunc(input, func(candidate string) string {
+		if len(candidate) > maxLen || opts.allowed(candidate) {
+			return candidate
+		}
+		decoded, ok := decodeBase64Text(candidate)
+		if !ok {
+			return candidate
+		}
+		rule, ok := firstSecretRule(decoded, rules, opts)
+		if !ok {
+			return candidate
+		}
+		opts.record(rule.ID, rule.SeverityLevel())
+		return opts.ruleReplacement(&rule, candidate)
+	})
+}
+
+// decodeBase64Text decodes s with the first base64 encoding that accepts it
+// and reports whether the result is printable UTF-8 text.
+func decodeBase64Text(s string) (string, bool) {
+	for _, enc := range base64Encodings {
+		b, err := enc.DecodeString(s)
+		if err != nil {
+			continue
+		}
+		if !utf8.Valid(b) {
+			return "", false
+		}
+		for _, r := range string(b) {
+			if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+				return "", false
+			}
+		}
+		return string(b), true
+	}
+	return "", false
+}
+
+// firstSecretRule returns the first rule not skipped by opts that finds a
+// secret in text which is not allowlisted.
+func firstSecretRule(text string, rules []Rule, opts MaskOptions) (Rule, bool) {
+	lowerText := strings.ToLower(text)
+	for _, rule := range rules {
+		if opts.skips(rule) || !hasKeyword(text, lowerText, rule) {
+			continue
+		}
+		for _, sp := range secretSpans(text, rule) {
+			if secret := text[sp.start:sp.end]; !opts.allowed(secret) && !opts.weak(rule.SeverityLevel(), secret) {
+				return rule, true
+			}
+		}
+	}
+	return Rule{}, false
+}
@@ -0,0 +1,24 @@
+package secret
+
+import (
+	"encoding/base64"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// validBasicCredentials reports whether s is the base64 of user:pass
+// credentials, as sent with HTTP Basic authentication: printable UTF-8
+// text with a colon after a non-empty user name.
+func validBasicCredentials(s string) bool {
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil || !utf8.Valid(b) {
+		return false
+	}
+	for _, r := range string(b) {
+		if !unicode.IsPrint(r) {
+			return false
+		}
+	}
+	return strings.IndexByte(string(b), ':') > 0
+}
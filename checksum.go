@@ -0,0 +1,31 @@
+package secret
+
+import (
+	"hash/crc32"
+	"strings"
+)
+
+// base62Alphabet is the digit order GitHub uses for token checksums.
+const base62Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// validGitHubToken reports whether token, such as ghp_ followed by 36
+// characters, ends in the checksum GitHub appends to its tokens: the CRC32
+// of the 30 random characters after the prefix, base62 encoded and padded
+// to 6 digits.
+func validGitHubToken(token string) bool {
+	_, body, ok := strings.Cut(token, "_")
+	if !ok || len(body) != 36 {
+		return false
+	}
+	return body[30:] == base62(crc32.ChecksumIEEE([]byte(body[:30])), 6)
+}
+
+// base62 encodes n in base 62, left-padded with zeros to width digits.
+func base62(n uint32, width int) string {
+	digits := make([]byte, width)
+	for i := width - 1; i >= 0; i-- {
+		digits[i] = base62Alphabet[n%62]
+		n /= 62
+	}
+	return string(digits)
+}
@@ -0,0 +1,28 @@
+//go:build js && wasm
+
+// Command secret-wasm exposes the masker to JavaScript when compiled to
+// WebAssembly:
+//
+//	GOOS=js GOARCH=wasm go build -o secret.wasm ./cmd/secret-wasm
+//
+// Once the module has been started with wasm_exec.js it defines a global
+// function maskSecrets(text) that returns text with the builtin rules
+// applied.
+package main
+
+import (
+	"syscall/js"
+
+	"secret"
+)
+
+func main() {
+	js.Global().Set("maskSecrets", js.FuncOf(func(this js.Value, args []js.Value) any {
+		if len(args) != 1 || args[0].Type() != js.TypeString {
+			return js.Null()
+		}
+		return secret.MaskSecretsOnString(args[0].String(), secret.BuiltinRules)
+	}))
+	// Keep the Go runtime alive so the function stays callable.
+	select {}
+}
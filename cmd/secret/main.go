@@ -0,0 +1,321 @@
+// Command secret masks secrets in a log read from stdin or a file.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"secret"
+)
+
+const usage = `usage: secret [flags]
+
+Reads a log from stdin (or -f file), masks any secrets in it and writes the
+result to stdout.
+
+Flags:
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes the command line and returns the process exit code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("secret", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		fs.PrintDefaults()
+	}
+	var cfg config
+	fs.StringVar(&cfg.file, "f", "", "read input from `file` instead of stdin")
+	fs.Func("rules", "load additional patterns from a patterns.yaml `file`; repeat to layer files, later ones overriding patterns by name", func(name string) error {
+		cfg.rulesFiles = append(cfg.rulesFiles, name)
+		return nil
+	})
+	fs.StringVar(&cfg.denylist, "denylist", "", "always mask the values whose hashes are listed in `file`, one \"sha256 length\" per line")
+	fs.StringVar(&cfg.tags, "tags", "", "only apply the builtin rules with one of these comma-separated `tags`, e.g. cloud,vcs")
+	fs.StringVar(&cfg.minSeverity, "min-severity", "", "only mask secrets of at least this `level` (LOW, MEDIUM, HIGH, CRITICAL)")
+	fs.StringVar(&cfg.failOn, "fail-on", "", "exit with status 1 after writing the output if a secret of at least this `level` was found")
+	fs.StringVar(&cfg.mask, "mask", secret.DefaultReplacement, "replacement `text` for each secret")
+	fs.BoolVar(&cfg.sameLength, "same-length", false, "replace each secret character with * so column-aligned output stays aligned")
+	fs.BoolVar(&cfg.kubernetes, "k8s", false, "treat the input as Kubernetes YAML manifests and mask all Secret data")
+	fs.BoolVar(&cfg.yaml, "yaml", false, "treat the input as YAML documents, such as Helm values, and mask the values of secret-named keys")
+	fs.BoolVar(&cfg.binary, "binary", false, "mask the input as raw bytes without assuming lines, for output mixed with binary or control bytes")
+	fs.BoolVar(&cfg.report, "report", false, "list the secrets found instead of masking them")
+	fs.BoolVar(&cfg.json, "json", false, "write one JSON object with the masked text and its findings per line")
+	fs.BoolVar(&cfg.env, "env", false, "also mask the values of KEY=value lines whose KEY names a secret, as in env output")
+	fs.StringVar(&cfg.manifest, "manifest", "", "write a summary of the masked secrets by rule, without the secrets, to `file`; CSV if it ends in .csv, JSON otherwise")
+	fs.BoolVar(&cfg.timing, "timing", false, "write how long masking took to stderr when done")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return 2
+	}
+
+	if err := maskCommand(cfg, stdin, stdout, stderr); err != nil {
+		fmt.Fprintf(stderr, "secret: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// config holds the command line flags.
+type config struct {
+	file        string
+	rulesFiles  []string
+	denylist    string
+	tags        string
+	minSeverity string
+	failOn      string
+	manifest    string
+	mask        string
+	sameLength  bool
+	kubernetes  bool
+	yaml        bool
+	binary      bool
+	report      bool
+	json        bool
+	env         bool
+	timing      bool
+}
+
+// slowRuleBudget is the time a rule may take on one line before it counts
+// as slow; rules that are slow on several lines in a row are disabled.
+const slowRuleBudget = 100 * time.Millisecond
+
+// maskCommand masks the input selected by the flags and writes it to
+// stdout. Warnings go to stderr.
+func maskCommand(cfg config, stdin io.Reader, stdout, stderr io.Writer) (err error) {
+	if err := checkModes(cfg); err != nil {
+		return err
+	}
+	opts := secret.StreamOptions{Options: secret.MaskOptions{Replacement: cfg.mask, PreserveLength: cfg.sameLength}, Binary: cfg.binary}
+
+	if cfg.minSeverity != "" {
+		level, err := secret.ParseSeverity(cfg.minSeverity)
+		if err != nil {
+			return err
+		}
+		opts.Options.MinSeverity = level
+	}
+	if cfg.failOn != "" {
+		if cfg.json || cfg.kubernetes || cfg.yaml {
+			return errors.New("-fail-on cannot be combined with -json, -k8s or -yaml")
+		}
+		level, err := secret.ParseSeverity(cfg.failOn)
+		if err != nil {
+			return err
+		}
+		opts.FailOn = level
+	}
+	if cfg.manifest != "" {
+		// The manifest counts what the rules masked in a stream; detectors
+		// report no findings and the other modes do not stream.
+		if cfg.json || cfg.kubernetes || cfg.yaml || cfg.report || cfg.env || cfg.denylist != "" {
+			return errors.New("-manifest cannot be combined with -json, -k8s, -yaml, -report, -env or -denylist")
+		}
+		manifest := &secret.Manifest{File: cfg.file}
+		opts.OnFinding = manifest.Add
+		defer func() {
+			// The manifest is written as long as the output was, also
+			// when -fail-on was reached.
+			var found *secret.SecretsFoundError
+			if err == nil || errors.As(err, &found) {
+				if werr := writeManifest(cfg.manifest, manifest); werr != nil {
+					err = werr
+				}
+			}
+		}()
+	}
+	if cfg.timing {
+		timing := &secret.Timing{}
+		opts.Options.Metrics = timing
+		defer func(start time.Time) {
+			fmt.Fprintf(stderr, "secret: %v, total %v\n", timing.Summary(), time.Since(start).Round(time.Microsecond))
+		}(time.Now())
+	}
+	if cfg.env {
+		// Report mode lists what the rules find; the detector would be
+		// skipped and its secrets left out of a seemingly clean report.
+		if cfg.report {
+			return errors.New("-env cannot be combined with -report")
+		}
+		opts.Options.Detectors = append(opts.Options.Detectors, secret.NewEnvDetector(nil))
+	}
+	if cfg.denylist != "" {
+		if cfg.report {
+			return errors.New("-denylist cannot be combined with -report")
+		}
+		hashed, err := secret.ReadDenylistHashes(cfg.denylist)
+		if err != nil {
+			return err
+		}
+		opts.Options.Detectors = append(opts.Options.Detectors, secret.NewDenylist(nil, hashed))
+	}
+
+	rules := secret.BuiltinRules
+	if cfg.tags != "" {
+		rules = secret.RulesByTag(strings.Split(cfg.tags, ",")...)
+	}
+	if len(cfg.rulesFiles) > 0 {
+		patterns, err := secret.ReadPatternFiles(cfg.rulesFiles...)
+		if err != nil {
+			return err
+		}
+		extra, err := secret.PatternsToRules(patterns)
+		if err != nil {
+			return err
+		}
+		rules = append(rules[:len(rules):len(rules)], extra...)
+		ids := make([]string, len(extra))
+		for i, rule := range extra {
+			ids[i] = rule.ID
+		}
+		opts.Options.SlowRules = &secret.SlowRuleGuard{
+			Budget: slowRuleBudget,
+			// Only the user's patterns may be disabled; builtin rules
+			// always run.
+			Rules: ids,
+			OnDisable: func(id string, timing secret.RuleTiming) {
+				fmt.Fprintf(stderr, "secret: warning: disabled rule %s, which took up to %v per line\n", id, timing.Max.Round(time.Millisecond))
+			},
+		}
+	}
+	if cfg.tags != "" || len(cfg.rulesFiles) > 0 {
+		opts.Registry = secret.NewRegistry(rules)
+	}
+
+	input := stdin
+	if cfg.file != "" {
+		f, err := os.Open(cfg.file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		input = f
+	}
+
+	if cfg.report {
+		return report(input, stdout, opts)
+	}
+	if cfg.kubernetes {
+		return maskYAML(input, stdout, opts, secret.MaskKubernetesManifests)
+	}
+	if cfg.yaml {
+		return maskYAML(input, stdout, opts, secret.MaskYAML)
+	}
+	if cfg.json {
+		return secret.MaskSecretsJSONLines(input, stdout, activeRules(opts), opts.Options)
+	}
+
+	_, err = io.Copy(stdout, secret.MaskSecretsReaderWithOptions(input, opts))
+	return err
+}
+
+// checkModes returns an error if more than one of the flags that select
+// how the input is read or written is set.
+func checkModes(cfg config) error {
+	var set []string
+	for _, mode := range []struct {
+		flag string
+		on   bool
+	}{
+		{"-report", cfg.report},
+		{"-k8s", cfg.kubernetes},
+		{"-yaml", cfg.yaml},
+		{"-json", cfg.json},
+		{"-binary", cfg.binary},
+	} {
+		if mode.on {
+			set = append(set, mode.flag)
+		}
+	}
+	if len(set) > 1 {
+		return fmt.Errorf("%s cannot be combined", strings.Join(set, ", "))
+	}
+	return nil
+}
+
+// writeManifest writes the manifest to filename, as CSV if it ends in .csv
+// and as JSON otherwise.
+func writeManifest(filename string, manifest *secret.Manifest) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	write := manifest.WriteJSON
+	if strings.EqualFold(filepath.Ext(filename), ".csv") {
+		write = manifest.WriteCSV
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// report writes one line per secret found in input, as
+// line:column: SEVERITY rule-id snippet. Findings of rules with a confidence
+// carry it after the rule ID, as confidence=HIGH. Once all are written, it
+// returns a *secret.SecretsFoundError if opts.FailOn was reached.
+func report(input io.Reader, stdout io.Writer, opts secret.StreamOptions) error {
+	findings, err := secret.ReportSecrets(input, io.Discard, activeRules(opts))
+	if err != nil {
+		return err
+	}
+	failures := 0
+	for _, f := range findings {
+		level, _ := secret.ParseSeverity(f.Severity)
+		if level < opts.Options.MinSeverity {
+			continue
+		}
+		if opts.FailOn > secret.SeverityUnknown && level >= opts.FailOn {
+			failures++
+		}
+		id := f.RuleID
+		if f.Confidence != "" {
+			id += " confidence=" + f.Confidence
+		}
+		if _, err := fmt.Fprintf(stdout, "%d:%d: %s %s %s\n", f.Line, f.Column, f.Severity, id, f.Snippet); err != nil {
+			return err
+		}
+	}
+	if failures > 0 {
+		return &secret.SecretsFoundError{Severity: opts.FailOn, Count: failures}
+	}
+	return nil
+}
+
+// maskYAML masks YAML documents with mask, such as MaskKubernetesManifests.
+// The input is read whole because it is parsed.
+func maskYAML(input io.Reader, stdout io.Writer, opts secret.StreamOptions, mask func([]byte, []secret.Rule, secret.MaskOptions) ([]byte, error)) error {
+	data, err := io.ReadAll(input)
+	if err != nil {
+		return err
+	}
+	masked, err := mask(data, activeRules(opts), opts.Options)
+	if err != nil {
+		return err
+	}
+	_, err = stdout.Write(masked)
+	return err
+}
+
+// activeRules returns the rules of the registry in opts, or BuiltinRules
+// when there is none.
+func activeRules(opts secret.StreamOptions) []secret.Rule {
+	if opts.Registry != nil {
+		return opts.Registry.Snapshot()
+	}
+	return secret.BuiltinRules
+}
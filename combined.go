@@ -0,0 +1,100 @@
+package secret
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// CombinedMasker applies many rules with a single regex scan per input by
+// OR-joining their patterns into one expression with a named group per rule.
+//
+// Alternation is leftmost-first, so where matches of different joined rules
+// overlap only one of them is found; the result can then differ from
+// MaskSecretsOnString, which masks the union of all matches. Rules with a
+// SecretGroupName need the submatch indices of their own regex to mask just
+// the secret group, and rules with a minimum Entropy or a Validate func need
+// their secrets checked, so they are not joined and run one at a time. All
+// rules are matched against the original input and replaced in one pass.
+//
+// A large alternation loses the literal-prefix optimisations of the
+// individual regexes, so whether this beats keyword-gated sequential masking
+// depends on the rule set; compare with BenchmarkCombinedMasker.
+type CombinedMasker struct {
+	re *regexp.Regexp
+	// groups holds the index of each joined rule's group in re.
+	groups []int
+	// joined holds the rule behind each group.
+	joined []Rule
+	// fallback holds the rules applied one at a time.
+	fallback []Rule
+}
+
+// NewCombinedMasker builds a CombinedMasker for rules.
+func NewCombinedMasker(rules []Rule) (*CombinedMasker, error) {
+	c := &CombinedMasker{}
+
+	var alternatives []string
+	for _, rule := range rules {
+		if rule.SecretGroupName != "" || rule.Entropy > 0 || rule.Validate != nil {
+			c.fallback = append(c.fallback, rule)
+			continue
+		}
+		// Wrapping each pattern in its own group scopes any inline flags
+		// such as (?i) to that rule.
+		alternatives = append(alternatives, fmt.Sprintf("(?P<rule%d>%s)", len(alternatives), rule.Regex.String()))
+		c.joined = append(c.joined, rule)
+	}
+	if len(alternatives) == 0 {
+		return c, nil
+	}
+
+	re, err := regexp.Compile(strings.Join(alternatives, "|"))
+	if err != nil {
+		return nil, fmt.Errorf("compiling combined regex: %w", err)
+	}
+	c.re = re
+	for i := range alternatives {
+		c.groups = append(c.groups, re.SubexpIndex(fmt.Sprintf("rule%d", i)))
+	}
+	return c, nil
+}
+
+// Mask masks every secret in input according to opts. ANSI escapes and
+// LineTimeout are not handled.
+func (c *CombinedMasker) Mask(input string, opts MaskOptions) string {
+	spans := ruleSpans(input, c.fallback, opts)
+	if c.re != nil {
+		spans = mergeSpans(append(spans, c.combinedSpans(input, opts)...))
+	}
+	maskedInput, protected := replaceSpans(input, spans, opts)
+	return applyDetectors(maskedInput, protected, opts)
+}
+
+// combinedSpans returns the secrets found by the combined regex.
+func (c *CombinedMasker) combinedSpans(input string, opts MaskOptions) []span {
+	var spans []span
+	for _, m := range c.re.FindAllStringSubmatchIndex(input, -1) {
+		for i, g := range c.groups {
+			start, end := m[2*g], m[2*g+1]
+			if start < 0 {
+				continue
+			}
+			rule := &c.joined[i]
+			if opts.skips(*rule) {
+				break
+			}
+			if opts.placeholder(input[start:end]) {
+				spans = append(spans, span{start: start, end: end, keep: true})
+				break
+			}
+			if opts.allowed(input[start:end]) || opts.weak(rule.SeverityLevel(), input[start:end]) {
+				break
+			}
+			opts.record(rule.ID, rule.SeverityLevel())
+			spans = append(spans, span{start: start, end: end, rule: rule})
+			break
+		}
+	}
+	return spans
+}
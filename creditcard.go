@@ -0,0 +1,66 @@
+package secret
+
+import (
+	"regexp"
+	"strings"
+)
+
+// CreditCardDetector masks payment card numbers (PANs). Candidates are runs
+// of 13 to 19 digits, optionally separated by single spaces or dashes, and
+// are only masked when they pass the Luhn checksum, which rules out most
+// order numbers, timestamps and other long digit runs.
+type CreditCardDetector struct {
+	// KeepSeparators masks each digit individually and leaves spaces and
+	// dashes in place, e.g. ****-****-****-****. Like PreserveLength, it
+	// fixes the format of the output, so it overrides MaskOptions.Masker
+	// and the options that choose a replacement, such as TokenKey, and
+	// cards are not marked by Repeats. Otherwise the whole number is
+	// replaced like any other secret.
+	KeepSeparators bool
+}
+
+var creditCardRegex = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+
+// Mask replaces every Luhn-valid card number in input.
+func (d CreditCardDetector) Mask(input string, opts MaskOptions) string {
+	return creditCardRegex.ReplaceAllStringFunc(input, func(candidate string) string {
+		if !LuhnValid(candidate) || opts.allowed(candidate) {
+			return candidate
+		}
+		opts.record("credit-card", SeverityUnknown)
+		if !d.KeepSeparators {
+			return opts.replacement("credit-card", candidate)
+		}
+		return strings.Map(func(r rune) rune {
+			if '0' <= r && r <= '9' {
+				return maskRune
+			}
+			return r
+		}, candidate)
+	})
+}
+
+// LuhnValid reports whether the digits in s pass the Luhn checksum. Spaces
+// and dashes are ignored; any other non-digit makes s invalid.
+func LuhnValid(s string) bool {
+	sum, n := 0, 0
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c == ' ' || c == '-' {
+			continue
+		}
+		if c < '0' || c > '9' {
+			return false
+		}
+		digit := int(c - '0')
+		if n%2 == 1 {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		n++
+	}
+	return n > 0 && sum%10 == 0
+}
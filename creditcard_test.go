@@ -0,0 +1,85 @@
+package secret_test
+
+import (
+	"secret"
+	"testing"
+)
+
+func TestCreditCardDetector(t *testing.T) {
+	tests := []struct {
+		name     string
+		detector secret.CreditCardDetector
+		input    string
+		want     string
+	}{
+		{
+			name:  "visa",
+			input: "card 4111 1111 1111 1111 charged",
+			want:  "card ****** charged",
+		},
+		{
+			name:     "mastercard keeping separators",
+			detector: secret.CreditCardDetector{KeepSeparators: true},
+			input:    "card 5555-5555-5555-4444 charged",
+			want:     "card ****-****-****-**** charged",
+		},
+		{
+			name:  "sample card",
+			input: "My credit card number is 4095-2609-9393-4932",
+			want:  "My credit card number is ******",
+		},
+		{
+			name:  "fails luhn",
+			input: "order 4111111111111112 shipped",
+			want:  "order 4111111111111112 shipped",
+		},
+		{
+			name:  "too short",
+			input: "ticket 411111111111",
+			want:  "ticket 411111111111",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := secret.MaskOptions{Detectors: []secret.Detector{tt.detector}}
+			if got := secret.MaskSecretsOnStringWithOptions(tt.input, secret.BuiltinRules, opts); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLuhnValid(t *testing.T) {
+	valid := []string{"4111111111111111", "5555 5555 5555 4444", "378282246310005"}
+	for _, s := range valid {
+		if !secret.LuhnValid(s) {
+			t.Errorf("LuhnValid(%q) = false, want true", s)
+		}
+	}
+	invalid := []string{"4111111111111112", "", "4111x11111111111"}
+	for _, s := range invalid {
+		if secret.LuhnValid(s) {
+			t.Errorf("LuhnValid(%q) = true, want false", s)
+		}
+	}
+}
+
+func TestCreditCardDetectorMasker(t *testing.T) {
+	const input = "card 5555-5555-5555-4444 charged"
+	masker := secret.FixedMasker{Replacement: "[vault:card]"}
+	tests := []struct {
+		detector secret.CreditCardDetector
+		want     string
+	}{
+		{secret.CreditCardDetector{}, "card [vault:card] charged"},
+		// KeepSeparators fixes the format and overrides the Masker.
+		{secret.CreditCardDetector{KeepSeparators: true}, "card ****-****-****-**** charged"},
+	}
+	for _, tt := range tests {
+		opts := secret.MaskOptions{Masker: masker, Detectors: []secret.Detector{tt.detector}}
+		if got := secret.MaskSecretsOnStringWithOptions(input, nil, opts); got != tt.want {
+			t.Errorf("KeepSeparators %v: got %q, want %q", tt.detector.KeepSeparators, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package secret
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Denylist masks known secret values, such as credentials just rotated out
+// of a vault, wherever they occur in the input, also inside longer text and
+// whether or not any rule matches them. Add it to MaskOptions.Detectors; its
+// secrets are counted as "denylist".
+//
+// Plaintext values are found with a single Aho-Corasick pass over the input.
+// Values known only by their SHA-256, so that the list itself holds no
+// secrets, are found by hashing every stretch of the input as long as one of
+// them, so masking costs one hash per input byte and distinct length.
+type Denylist struct {
+	values []string
+	ac     *ahoCorasick
+	hashes map[[sha256.Size]byte]bool
+	// lengths are the distinct lengths of the hashed values.
+	lengths []int
+}
+
+// HashedValue identifies a denylisted value by its SHA-256 and its length
+// in bytes.
+type HashedValue struct {
+	Sum    [sha256.Size]byte
+	Length int
+}
+
+// HashValue returns the HashedValue of value.
+func HashValue(value string) HashedValue {
+	return HashedValue{Sum: sha256.Sum256([]byte(value)), Length: len(value)}
+}
+
+// String formats h as a line of a denylist file: the hex SHA-256 and the
+// length, separated by a space.
+func (h HashedValue) String() string {
+	return hex.EncodeToString(h.Sum[:]) + " " + strconv.Itoa(h.Length)
+}
+
+// NewDenylist returns a denylist of the plaintext values and the hashed
+// values. Empty values are ignored.
+func NewDenylist(values []string, hashed []HashedValue) *Denylist {
+	d := &Denylist{hashes: make(map[[sha256.Size]byte]bool)}
+	for _, v := range values {
+		if v != "" {
+			d.values = append(d.values, v)
+		}
+	}
+	if len(d.values) > 0 {
+		d.ac = newAhoCorasick(d.values)
+	}
+	seen := make(map[int]bool)
+	for _, h := range hashed {
+		if h.Length <= 0 {
+			continue
+		}
+		d.hashes[h.Sum] = true
+		if !seen[h.Length] {
+			seen[h.Length] = true
+			d.lengths = append(d.lengths, h.Length)
+		}
+	}
+	sort.Ints(d.lengths)
+	return d
+}
+
+// ReadDenylistHashes reads the hashed values of a denylist file, one per
+// line as written by HashedValue.String. Blank lines and lines starting
+// with # are skipped.
+func ReadDenylistHashes(filename string) ([]HashedValue, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readDenylistHashes(filename, f)
+}
+
+// readDenylistHashes parses the denylist file read from r; name locates
+// errors.
+func readDenylistHashes(name string, r io.Reader) ([]HashedValue, error) {
+	var hashed []HashedValue
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		h, err := parseHashedValue(text)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", name, line, err)
+		}
+		hashed = append(hashed, h)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", name, err)
+	}
+	return hashed, nil
+}
+
+// parseHashedValue parses a line formatted by HashedValue.String.
+func parseHashedValue(text string) (HashedValue, error) {
+	var h HashedValue
+	fields := strings.Fields(text)
+	if len(fields) != 2 {
+		return h, fmt.Errorf("want a SHA-256 and a length, got %q", text)
+	}
+	sum, err := hex.DecodeString(fields[0])
+	if err != nil || len(sum) != sha256.Size {
+		return h, fmt.Errorf("invalid SHA-256 %q", fields[0])
+	}
+	copy(h.Sum[:], sum)
+	if h.Length, err = strconv.Atoi(fields[1]); err != nil || h.Length <= 0 {
+		return h, fmt.Errorf("invalid length %q", fields[1])
+	}
+	return h, nil
+}
+
+// Mask replaces every occurrence of a denylisted value in input.
+// Overlapping occurrences are masked as one.
+func (d *Denylist) Mask(input string, opts MaskOptions) string {
+	var spans []span
+	if d.ac != nil {
+		d.ac.scan(input, func(k, end int) {
+			value := d.values[k]
+			if input[end-len(value):end] == value {
+				spans = append(spans, span{start: end - len(value), end: end})
+			}
+		})
+	}
+	for _, n := range d.lengths {
+		for start := 0; start+n <= len(input); start++ {
+			if d.hashes[sha256.Sum256([]byte(input[start:start+n]))] {
+				spans = append(spans, span{start: start, end: start + n})
+			}
+		}
+	}
+	if spans == nil {
+		return input
+	}
+
+	var sb strings.Builder
+	last := 0
+	for _, sp := range mergeSpans(spans) {
+		value := input[sp.start:sp.end]
+		if opts.allowed(value) {
+			continue
+		}
+		opts.record("denylist", SeverityUnknown)
+		sb.WriteString(input[last:sp.start])
+		sb.WriteString(opts.replacement("denylist", value))
+		last = sp.end
+	}
+	sb.WriteString(input[last:])
+	return sb.String()
+}
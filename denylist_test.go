@@ -0,0 +1,62 @@
+package secret_test
+
+import (
+	"os"
+	"path/filepath"
+	"secret"
+	"strings"
+	"testing"
+)
+
+func TestDenylist(t *testing.T) {
+	const rotated = "vault-rotated-8f2c"
+	d := secret.NewDenylist([]string{rotated, "Corr3ctHorse"}, nil)
+	opts := secret.MaskOptions{Detectors: []secret.Detector{d}}
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"password " + rotated, "password ******"},
+		{"url https://user:" + rotated + "@db/x", "url https://user:******@db/x"},
+		{"prefix" + rotated + "suffix and Corr3ctHorse", "prefix******suffix and ******"},
+		{"case differs: correcthorse", "case differs: correcthorse"},
+	}
+	for _, tt := range tests {
+		if got := secret.MaskSecretsOnStringWithOptions(tt.input, secret.BuiltinRules, opts); got != tt.want {
+			t.Errorf("Mask(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestDenylistHashes(t *testing.T) {
+	const rotated = "hunter2-but-longer"
+	file := filepath.Join(t.TempDir(), "denylist.txt")
+	data := "# rotated 2024-05-24\n\n" + secret.HashValue(rotated).String() + "\n"
+	if err := os.WriteFile(file, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	hashed, err := secret.ReadDenylistHashes(file)
+	if err != nil {
+		t.Fatalf("ReadDenylistHashes: %v", err)
+	}
+	if strings.Contains(data, rotated) {
+		t.Fatal("denylist file holds the plaintext value")
+	}
+
+	opts := secret.MaskOptions{Detectors: []secret.Detector{secret.NewDenylist(nil, hashed)}}
+	input := "login x" + rotated + "y ok"
+	if got, want := secret.MaskSecretsOnStringWithOptions(input, nil, opts), "login x******y ok"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestReadDenylistHashesError(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "denylist.txt")
+	if err := os.WriteFile(file, []byte(secret.HashValue("x").String()+"\nnot-a-hash 12\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := secret.ReadDenylistHashes(file); err == nil || !strings.Contains(err.Error(), ":2:") {
+		t.Errorf("error = %v, want error locating line 2", err)
+	}
+}
@@ -0,0 +1,69 @@
+package secret
+
+import (
+	"math"
+	"regexp"
+)
+
+// EntropyDetector masks high-entropy tokens such as random API keys that no
+// provider-specific rule recognises. A token is any run of base64 or
+// URL-safe base64 characters; it is masked when its length is within
+// [MinLength, MaxLength] and its Shannon entropy exceeds Threshold.
+type EntropyDetector struct {
+	// Threshold is the minimum entropy in bits per character.
+	Threshold float64
+	MinLength int
+	MaxLength int
+}
+
+// DefaultEntropyDetector is tuned so that random base64 and alphanumeric
+// tokens of 20 or more characters are masked while words, hex commit hashes
+// and ordinary identifiers are not.
+var DefaultEntropyDetector = EntropyDetector{
+	Threshold: 4.0,
+	MinLength: 20,
+	MaxLength: 512,
+}
+
+var entropyTokenRegex = regexp.MustCompile(`[A-Za-z0-9+/_\-]+={0,2}`)
+
+// ShannonEntropy returns the Shannon entropy of s in bits per byte.
+func ShannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	var counts [256]int
+	for i := 0; i < len(s); i++ {
+		counts[s[i]]++
+	}
+
+	var entropy float64
+	n := float64(len(s))
+	for _, c := range counts {
+		if c == 0 {
+			continue
+		}
+		p := float64(c) / n
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// IsHighEntropy reports whether token would be masked by the detector.
+func (d EntropyDetector) IsHighEntropy(token string) bool {
+	if len(token) < d.MinLength || (d.MaxLength > 0 && len(token) > d.MaxLength) {
+		return false
+	}
+	return ShannonEntropy(token) > d.Threshold
+}
+
+// Mask replaces every high-entropy token in input according to opts.
+func (d EntropyDetector) Mask(input string, opts MaskOptions) string {
+	return entropyTokenRegex.ReplaceAllStringFunc(input, func(token string) string {
+		if !d.IsHighEntropy(token) || opts.placeholder(token) || opts.allowed(token) {
+			return token
+		}
+		opts.record("high-entropy", SeverityUnknown)
+		return opts.replacement("high-entropy", token)
+	})
+}
@@ -0,0 +1,58 @@
+package secret_test
+
+import (
+	"secret"
+	"testing"
+)
+
+func TestShannonEntropy(t *testing.T) {
+	if got := secret.ShannonEntropy("aaaa"); got != 0 {
+		t.Errorf("ShannonEntropy(aaaa) = %v, want 0", got)
+	}
+	if got := secret.ShannonEntropy("abcd"); got != 2 {
+		t.Errorf("ShannonEntropy(abcd) = %v, want 2", got)
+	}
+}
+
+func TestEntropyDetector(t *testing.T) {
+	detector := secret.DefaultEntropyDetector
+	opts := secret.MaskOptions{Entropy: &detector}
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "base64 key",
+			input: `token = "q8Zx3n0vJ4mYtP2wK7sLr9dBfH1gQeUoVaCiXyN5TjE="`,
+			want:  `token = "******"`,
+		},
+		{
+			name:  "english sentence",
+			input: "The deployment finished successfully after rebuilding the container images",
+			want:  "The deployment finished successfully after rebuilding the container images",
+		},
+		{
+			name:  "commit hash",
+			input: "commit 4bc9465a06127ba00226593ea10b2f43021c536a",
+			want:  "commit 4bc9465a06127ba00226593ea10b2f43021c536a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := secret.MaskSecretsOnStringWithOptions(tt.input, secret.BuiltinRules, opts)
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEntropyDetectorDisabledByDefault(t *testing.T) {
+	input := `token = "q8Zx3n0vJ4mYtP2wK7sLr9dBfH1gQeUoVaCiXyN5TjE="`
+	if got := secret.MaskSecretsOnString(input, secret.BuiltinRules); got != input {
+		t.Errorf("MaskSecretsOnString(%q) = %q, want input unchanged", input, got)
+	}
+}
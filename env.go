@@ -0,0 +1,61 @@
+package secret
+
+import (
+	"regexp"
+	"strings"
+)
+
+// DefaultSensitiveEnvName matches environment variable names whose values
+// are treated as secrets by an EnvDetector created without a pattern. It
+// deliberately does not match PWD, the shell's working directory, nor
+// GIT_AUTHOR_NAME and GIT_AUTHOR_EMAIL.
+var DefaultSensitiveEnvName = regexp.MustCompile(`(?i)(SECRET|TOKEN|PASSWORD|PASSWD|_PWD$|API_?KEY|ACCESS_?KEY|PRIVATE_?KEY|CREDENTIALS?$|AUTH_?TOKEN|_AUTH$|DATABASE_URL|_DSN$|CONNECTION_STRING)`)
+
+// envLineRegex matches a KEY=value line as printed by env, printenv,
+// export -p or declare -x, with the optional quotes around the value.
+var envLineRegex = regexp.MustCompile(`^((?:export |declare -x )?)([A-Za-z_][A-Za-z0-9_]*)=(["']?)(.*?)(["']?)$`)
+
+// EnvDetector masks the values of environment variables in dumps such as
+// env output, one KEY=value per line, when the name matches a sensitive
+// pattern. The whole value is masked whatever its format; the name, the
+// = and any quotes are kept.
+type EnvDetector struct {
+	name *regexp.Regexp
+}
+
+// NewEnvDetector returns a detector for variables whose names match name,
+// or DefaultSensitiveEnvName when name is nil.
+func NewEnvDetector(name *regexp.Regexp) *EnvDetector {
+	if name == nil {
+		name = DefaultSensitiveEnvName
+	}
+	return &EnvDetector{name: name}
+}
+
+// Mask replaces the value of every sensitive KEY=value line in input. A
+// line holding a secret already masked by a rule is left as it is, so that
+// the value is not masked piecemeal around it.
+func (d *EnvDetector) Mask(input string, opts MaskOptions) string {
+	lines := strings.SplitAfter(input, "\n")
+	for i, line := range lines {
+		if i == 0 && opts.cutBefore || i == len(lines)-1 && opts.cutAfter {
+			continue
+		}
+		content, eol := splitEOL(line)
+		m := envLineRegex.FindStringSubmatch(content)
+		if m == nil || !d.name.MatchString(m[2]) {
+			continue
+		}
+		prefix, key, open, value, closing := m[1], m[2], m[3], m[4], m[5]
+		if open != closing {
+			// An unbalanced quote is part of the value.
+			value, open, closing = open+value+closing, "", ""
+		}
+		if value == "" || opts.placeholder(value) || opts.allowed(value) {
+			continue
+		}
+		opts.record("env-secret", SeverityUnknown)
+		lines[i] = prefix + key + "=" + open + opts.replacement("env-secret", value) + closing + eol
+	}
+	return strings.Join(lines, "")
+}
@@ -0,0 +1,54 @@
+package secret_test
+
+import (
+	"regexp"
+	"secret"
+	"testing"
+)
+
+func TestEnvDetector(t *testing.T) {
+	tests := []struct {
+		name     string
+		detector *secret.EnvDetector
+		input    string
+		want     string
+	}{
+		{"aws secret", secret.NewEnvDetector(nil), "AWS_SECRET_ACCESS_KEY=whatever", "AWS_SECRET_ACCESS_KEY=******"},
+		{"not sensitive", secret.NewEnvDetector(nil), "HOME=/root", "HOME=/root"},
+		{"working directory", secret.NewEnvDetector(nil), "PWD=/home/runner/work", "PWD=/home/runner/work"},
+		{"database url", secret.NewEnvDetector(nil), "DATABASE_URL=postgres://app@db/app", "DATABASE_URL=******"},
+		{"git author", secret.NewEnvDetector(nil), "GIT_AUTHOR_NAME=Jane Doe\nGIT_AUTHOR_EMAIL=jane@example.com", "GIT_AUTHOR_NAME=Jane Doe\nGIT_AUTHOR_EMAIL=jane@example.com"},
+		{"auth names", secret.NewEnvDetector(nil), "NPM_AUTH_TOKEN=abc\nREGISTRY_AUTH=def", "NPM_AUTH_TOKEN=******\nREGISTRY_AUTH=******"},
+		{"export quoted", secret.NewEnvDetector(nil), `declare -x GITHUB_TOKEN="abc def"`, `declare -x GITHUB_TOKEN="******"`},
+		{"unbalanced quote", secret.NewEnvDetector(nil), `export DB_PASSWORD="abc`, `export DB_PASSWORD=******`},
+		{"empty value", secret.NewEnvDetector(nil), "NPM_TOKEN=", "NPM_TOKEN="},
+		{"not at line start", secret.NewEnvDetector(nil), "echo GITHUB_TOKEN=abc", "echo GITHUB_TOKEN=abc"},
+		{"custom names", secret.NewEnvDetector(regexp.MustCompile(`^INTERNAL_`)), "INTERNAL_HOST=10.0.0.1\nAPI_TOKEN=abc", "INTERNAL_HOST=******\nAPI_TOKEN=abc"},
+		{
+			"dump",
+			secret.NewEnvDetector(nil),
+			"HOME=/root\nGITHUB_TOKEN=abc123\r\nSHELL=/bin/bash\n",
+			"HOME=/root\nGITHUB_TOKEN=******\r\nSHELL=/bin/bash\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := secret.MaskSecretsOnStringWithOptions(tt.input, nil, secret.MaskOptions{Detectors: []secret.Detector{tt.detector}})
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnvDetectorValueMaskedByRule(t *testing.T) {
+	// The URL rule masks the password; the rest of the value stays readable
+	// rather than being masked in pieces around it.
+	input := "DATABASE_URL=postgres://u:p4ssw0rd@h/db\nAPI_TOKEN=abc\n"
+	want := "DATABASE_URL=postgres://u:******@h/db\nAPI_TOKEN=******\n"
+	opts := secret.MaskOptions{Detectors: []secret.Detector{secret.NewEnvDetector(nil)}}
+	if got := secret.MaskSecretsOnStringWithOptions(input, secret.BuiltinRules, opts); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
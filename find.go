@@ -0,0 +1,150 @@
+package secret
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Finding describes a single secret located in an input string.
+type Finding struct {
+	RuleID   string `json:"ruleId"`
+	Severity string `json:"severity"`
+	// Confidence is the confidence of the rule, if it has one.
+	Confidence string `json:"confidence,omitempty"`
+	Title      string `json:"title"`
+	// Start and End are byte offsets of the secret in the original input.
+	Start int `json:"start"`
+	End   int `json:"end"`
+	// Line and Column locate Start; both are 1-based and Column counts
+	// bytes.
+	Line   int `json:"line"`
+	Column int `json:"column"`
+	// Match is the secret text, i.e. the rule's secret group when it has
+	// one and the whole regex match otherwise. It is never encoded as JSON.
+	Match string `json:"-"`
+	// Snippet is the whole regex match with the secret masked, e.g.
+	// facebook_secret: "******", so it can be shown without leaking it.
+	// Surrounding whitespace is trimmed.
+	Snippet string `json:"snippet"`
+	// Verified is set by VerifyFindings when the provider confirmed that
+	// the secret is a live credential.
+	Verified bool `json:"verified,omitempty"`
+}
+
+// FindSecrets reports every secret in input matched by the provided rules,
+// ordered by position. Unlike masking, all rules run against the original
+// input, so overlapping findings from different rules are all reported.
+// Findings at the same position are ordered as masking ranks their rules:
+// higher Priority first, then higher severity and confidence, then rule
+// order.
+func FindSecrets(input string, rules []Rule) []Finding {
+	var findings []Finding
+	var found []*Rule
+	lowerInput := strings.ToLower(input)
+
+	for i := range rules {
+		rule := &rules[i]
+		if !hasKeyword(input, lowerInput, *rule) {
+			continue
+		}
+		for _, m := range secretMatches(input, *rule) {
+			line := 1 + strings.Count(input[:m.secret.start], "\n")
+			findings = append(findings, Finding{
+				RuleID:     rule.ID,
+				Severity:   rule.Severity,
+				Confidence: rule.Confidence,
+				Title:      rule.Title,
+				Start:      m.secret.start,
+				End:        m.secret.end,
+				Line:       line,
+				Column:     m.secret.start - strings.LastIndexByte(input[:m.secret.start], '\n'),
+				Match:      input[m.secret.start:m.secret.end],
+				Snippet:    strings.TrimSpace(input[m.match.start:m.secret.start] + DefaultReplacement + input[m.secret.end:m.match.end]),
+			})
+			found = append(found, rule)
+		}
+	}
+
+	sort.Stable(findingsByPosition{findings, found})
+	return findings
+}
+
+// findingsByPosition sorts findings by Start, breaking ties with precedes
+// on the rules that found them, which are kept in the parallel rules slice.
+type findingsByPosition struct {
+	findings []Finding
+	rules    []*Rule
+}
+
+func (s findingsByPosition) Len() int { return len(s.findings) }
+
+func (s findingsByPosition) Less(i, j int) bool {
+	if s.findings[i].Start != s.findings[j].Start {
+		return s.findings[i].Start < s.findings[j].Start
+	}
+	return precedes(s.rules[i], s.rules[j])
+}
+
+func (s findingsByPosition) Swap(i, j int) {
+	s.findings[i], s.findings[j] = s.findings[j], s.findings[i]
+	s.rules[i], s.rules[j] = s.rules[j], s.rules[i]
+}
+
+// ReportSecrets is a dry run of masking: it copies r to w unchanged and
+// returns the secrets the rules find in it, with Line and Column set and
+// Start and End counted from the beginning of r. Private key blocks are
+// scanned whole, as masking streams do, so keys spanning several lines are
+// reported. Match is left empty so the report itself holds no secrets; use
+// Snippet to show them. Pass io.Discard as w when only the report is
+// wanted.
+func ReportSecrets(r io.Reader, w io.Writer, rules []Rule) ([]Finding, error) {
+	br := bufio.NewReader(r)
+	var findings []Finding
+	var block pemBlock
+	offset, line := 0, 1
+	// scan reports the findings in text, a line or a whole block, and
+	// moves past it.
+	scan := func(text string) {
+		content, _ := splitEOL(text)
+		for _, f := range FindSecrets(content, rules) {
+			f.Line += line - 1
+			f.Start += offset
+			f.End += offset
+			f.Match = ""
+			findings = append(findings, f)
+		}
+		offset += len(text)
+		line += strings.Count(text, "\n")
+	}
+	for {
+		text, err := br.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return findings, err
+		}
+		if _, werr := io.WriteString(w, text); werr != nil {
+			return findings, werr
+		}
+
+		if text != "" {
+			if taken, lines, complete := block.add(text); !taken {
+				scan(text)
+			} else if complete {
+				scan(strings.Join(lines, ""))
+			} else {
+				for _, l := range lines {
+					scan(l)
+				}
+			}
+		}
+
+		if err != nil {
+			for _, l := range block.take() {
+				scan(l)
+			}
+			return findings, nil
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package secret
+
+import "regexp"
+
+// DefaultGenericSecretKeys are the key names considered by a
+// GenericSecretDetector created without keys. They are broader than
+// DefaultAssignmentKeys because values must also look random.
+var DefaultGenericSecretKeys = []string{"secret", "token", "key", "password", "passwd", "pwd", "credential", "auth"}
+
+// GenericSecretDetector masks high-entropy hex and base64 values assigned
+// to sensitive-looking keys, such as secret: "<40 base64 characters>" or
+// key = <64 hex digits>, whatever provider issued them. Requiring both the
+// assignment and a random-looking value finds far fewer false positives
+// than the entropy detector alone. Values are matched like those of an
+// AssignmentDetector.
+type GenericSecretDetector struct {
+	// Hex decides which values made of hex digits only are masked.
+	Hex EntropyDetector
+	// Base64 decides which other values made of standard or URL-safe
+	// base64 characters, with optional padding, are masked. Values with
+	// any other character are never masked.
+	Base64 EntropyDetector
+
+	re *regexp.Regexp
+}
+
+// genericHexRegex and genericBase64Regex classify assigned values.
+var (
+	genericHexRegex    = regexp.MustCompile(`^[0-9A-Fa-f]+$`)
+	genericBase64Regex = regexp.MustCompile(`^[A-Za-z0-9+/_\-]+={0,2}$`)
+)
+
+// NewGenericSecretDetector returns a detector for the given key names, or
+// for DefaultGenericSecretKeys when none are given. Hex values need 32 or
+// more digits and base64 values 40 or more characters; the thresholds may
+// be adjusted before use.
+func NewGenericSecretDetector(keys ...string) *GenericSecretDetector {
+	if len(keys) == 0 {
+		keys = DefaultGenericSecretKeys
+	}
+	return &GenericSecretDetector{
+		Hex:    EntropyDetector{Threshold: 3.0, MinLength: 32, MaxLength: 512},
+		Base64: EntropyDetector{Threshold: 4.5, MinLength: 40, MaxLength: 512},
+		re:     assignmentRegex(keys),
+	}
+}
+
+// Mask replaces the high-entropy value of every sensitive assignment in
+// input.
+func (d *GenericSecretDetector) Mask(input string, opts MaskOptions) string {
+	return maskAssignments(input, d.re, "generic-secret", d.IsSecret, opts)
+}
+
+// IsSecret reports whether value, assigned to a sensitive key, would be
+// masked by the detector.
+func (d *GenericSecretDetector) IsSecret(value string) bool {
+	switch {
+	case genericHexRegex.MatchString(value):
+		return d.Hex.IsHighEntropy(value)
+	case genericBase64Regex.MatchString(value):
+		return d.Base64.IsHighEntropy(value)
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package secret_test
+
+import (
+	"secret"
+	"testing"
+)
+
+func TestGenericSecretDetector(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "base64 token",
+			input: `acme_secret: "tR7vX2qLm9PzKc4W8bNf1YhJ6sDg0Ae3UoQiVx5Z+k/="`,
+			want:  `acme_secret: "******"`,
+		},
+		{
+			name:  "hex key",
+			input: "signing_key = 9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+			want:  "signing_key = ******",
+		},
+		{
+			name:  "lorem ipsum",
+			input: `page_token: "Loremipsumdolorsitametconsecteturadipiscingelitseddoeiusmod"`,
+			want:  `page_token: "Loremipsumdolorsitametconsecteturadipiscingelitseddoeiusmod"`,
+		},
+		{
+			name:  "lorem ipsum with spaces",
+			input: `secret_note = "Lorem ipsum dolor sit amet, consectetur adipiscing elit"`,
+			want:  `secret_note = "Lorem ipsum dolor sit amet, consectetur adipiscing elit"`,
+		},
+		{
+			name:  "short value",
+			input: "token=abc123XYZ",
+			want:  "token=abc123XYZ",
+		},
+		{
+			name:  "random value under another key",
+			input: "request_id=tR7vX2qLm9PzKc4W8bNf1YhJ6sDg0Ae3UoQiVx5Z",
+			want:  "request_id=tR7vX2qLm9PzKc4W8bNf1YhJ6sDg0Ae3UoQiVx5Z",
+		},
+	}
+
+	opts := secret.MaskOptions{Detectors: []secret.Detector{secret.NewGenericSecretDetector()}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := secret.MaskSecretsOnStringWithOptions(tt.input, nil, opts); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenericSecretDetectorThresholds(t *testing.T) {
+	input := "api_key = 0123456789abcdef"
+	d := secret.NewGenericSecretDetector()
+	opts := secret.MaskOptions{Detectors: []secret.Detector{d}}
+	if got := secret.MaskSecretsOnStringWithOptions(input, nil, opts); got != input {
+		t.Errorf("default thresholds: got %q, want input unchanged", got)
+	}
+
+	d.Hex.MinLength = 16
+	if got, want := secret.MaskSecretsOnStringWithOptions(input, nil, opts), "api_key = ******"; got != want {
+		t.Errorf("MinLength 16: got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,122 @@
+package secret
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+
+	"github.com/BurntSushi/toml"
+)
+
+// gitleaksConfig is the subset of a gitleaks .toml configuration that maps
+// onto Rule.
+type gitleaksConfig struct {
+	Rules []gitleaksRule `toml:"rules"`
+}
+
+type gitleaksRule struct {
+	ID          string   `toml:"id"`
+	Description string   `toml:"description"`
+	Regex       string   `toml:"regex"`
+	SecretGroup int      `toml:"secretGroup"`
+	Entropy     float64  `toml:"entropy"`
+	Keywords    []string `toml:"keywords"`
+}
+
+// gitleaksSecretGroup is the name given to an unnamed gitleaks secretGroup.
+const gitleaksSecretGroup = "secret"
+
+// ReadGitleaksRules loads the rules of a gitleaks-style .toml file, so that
+// existing gitleaks rulesets can be reused. Each rule's description becomes
+// the Title, and its regex, keywords, secretGroup and entropy carry over;
+// like gitleaks, keywords are matched case-insensitively. gitleaks has no
+// severities, so the rules are MEDIUM. Rules without a regex, which only
+// match file paths, and allowlists are ignored. If any regex is invalid,
+// the returned error lists every offending rule.
+func ReadGitleaksRules(filename string) ([]Rule, error) {
+	var config gitleaksConfig
+	if _, err := toml.DecodeFile(filename, &config); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", filename, err)
+	}
+
+	rules := make([]Rule, 0, len(config.Rules))
+	var errs []error
+	for _, gr := range config.Rules {
+		if gr.Regex == "" {
+			continue
+		}
+		rule, err := gr.rule()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: rule %q: %w", filename, gr.ID, err))
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// rule converts gr into a Rule.
+func (gr gitleaksRule) rule() (Rule, error) {
+	re, err := regexp.Compile(gr.Regex)
+	if err != nil {
+		return Rule{}, err
+	}
+	rule := Rule{
+		ID:           gr.ID,
+		Severity:     "MEDIUM",
+		Title:        gr.Description,
+		Regex:        re,
+		Keywords:     gr.Keywords,
+		Entropy:      gr.Entropy,
+		foldKeywords: true,
+	}
+	if gr.SecretGroup > 0 {
+		if gr.SecretGroup > re.NumSubexp() {
+			return Rule{}, fmt.Errorf("secretGroup %d out of range, regex has %d groups", gr.SecretGroup, re.NumSubexp())
+		}
+		if name := re.SubexpNames()[gr.SecretGroup]; name != "" {
+			rule.SecretGroupName = name
+		} else if rule.Regex, err = nameGroup(gr.Regex, gr.SecretGroup, gitleaksSecretGroup); err != nil {
+			return Rule{}, err
+		} else {
+			rule.SecretGroupName = gitleaksSecretGroup
+		}
+	}
+	return rule, nil
+}
+
+// nameGroup recompiles expr with its capturing group number n given name,
+// since rules refer to their secret group by name.
+func nameGroup(expr string, n int, name string) (*regexp.Regexp, error) {
+	parsed, err := syntax.Parse(expr, syntax.Perl)
+	if err != nil {
+		return nil, err
+	}
+	var walk func(*syntax.Regexp) bool
+	walk = func(re *syntax.Regexp) bool {
+		if re.Op == syntax.OpCapture && re.Cap == n {
+			re.Name = name
+			return true
+		}
+		for _, sub := range re.Sub {
+			if walk(sub) {
+				return true
+			}
+		}
+		return false
+	}
+	walk(parsed)
+
+	re, err := regexp.Compile(parsed.String())
+	if err != nil {
+		return nil, err
+	}
+	if re.SubexpIndex(name) != n {
+		return nil, fmt.Errorf("regex already has a group named %q", name)
+	}
+	return re, nil
+}
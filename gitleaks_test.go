@@ -0,0 +1,79 @@
+package secret_test
+
+import (
+	"os"
+	"path/filepath"
+	"secret"
+	"strings"
+	"testing"
+)
+
+const gitleaksConfig = `title = "team rules"
+
+[[rules]]
+id = "internal-token"
+description = "Internal Service Token"
+regex = '''itk_[0-9a-f]{16}'''
+keywords = ["ITK_"]
+
+[[rules]]
+id = "acme-api-key"
+description = "ACME API Key"
+regex = '''acme_key\s*=\s*([A-Za-z0-9]{20})'''
+secretGroup = 1
+entropy = 3.0
+keywords = ["acme_key"]
+
+[[rules]]
+id = "pkcs12-file"
+description = "PKCS12 file"
+path = '''\.p12$'''
+`
+
+func writeGitleaksConfig(t *testing.T, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "gitleaks.toml")
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadGitleaksRules(t *testing.T) {
+	rules, err := secret.ReadGitleaksRules(writeGitleaksConfig(t, gitleaksConfig))
+	if err != nil {
+		t.Fatalf("ReadGitleaksRules: %v", err)
+	}
+	if len(rules) != 2 {
+		t.Fatalf("got %d rules, want 2", len(rules))
+	}
+	if rules[0].Title != "Internal Service Token" || rules[1].SecretGroupName == "" {
+		t.Errorf("unexpected rules %+v", rules)
+	}
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"auth itk_0123456789abcdef ok", "auth ****** ok"},
+		{"acme_key = Zx81Qm4Tb7Lp0Wc3Ry6N", "acme_key = ******"},
+		// Below the rule's entropy threshold.
+		{"acme_key = aaaaaaaaaaaaaaaaaaaa", "acme_key = aaaaaaaaaaaaaaaaaaaa"},
+	}
+	for _, tt := range tests {
+		if got := secret.MaskSecretsOnString(tt.input, rules); got != tt.want {
+			t.Errorf("MaskSecretsOnString(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestReadGitleaksRulesInvalidRegex(t *testing.T) {
+	config := `[[rules]]
+id = "broken"
+regex = '''broken_([0-9]'''
+`
+	_, err := secret.ReadGitleaksRules(writeGitleaksConfig(t, config))
+	if err == nil || !strings.Contains(err.Error(), `"broken"`) {
+		t.Errorf("ReadGitleaksRules error = %v, want error naming the rule", err)
+	}
+}
@@ -2,4 +2,8 @@ module secret
 
 go 1.22.3
 
-require gopkg.in/yaml.v3 v3.0.1 // indirect
+require (
+	github.com/BurntSushi/toml v1.6.0
+	golang.org/x/text v0.16.0
+	gopkg.in/yaml.v3 v3.0.1
+)
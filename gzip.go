@@ -0,0 +1,49 @@
+package secret
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+)
+
+// gzipMagic is the two-byte header every gzip member starts with.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// MaskSecretsReaderGzip is like MaskSecretsReader but accepts gzip-compressed
+// input. If r starts with the gzip magic bytes it is decompressed before
+// masking, and when recompress is set the masked output is gzip-compressed
+// again. Input that is not gzip is masked as plain text and never compressed.
+//
+// The returned error reports a malformed gzip header; errors in the
+// compressed data surface from the returned reader.
+func MaskSecretsReaderGzip(r io.Reader, recompress bool) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(len(gzipMagic))
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if !bytes.Equal(magic, gzipMagic) {
+		return MaskSecretsReader(br), nil
+	}
+
+	zr, err := gzip.NewReader(br)
+	if err != nil {
+		return nil, err
+	}
+	masked := MaskSecretsReader(zr)
+	if !recompress {
+		return masked, nil
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		zw := gzip.NewWriter(pw)
+		_, err := io.Copy(zw, masked)
+		if closeErr := zw.Close(); err == nil {
+			err = closeErr
+		}
+		pw.CloseWithError(err)
+	}()
+	return pr, nil
+}
@@ -0,0 +1,56 @@
+package secret_test
+
+import (
+	"secret"
+	"testing"
+)
+
+func TestIBANDetector(t *testing.T) {
+	tests := []struct {
+		name     string
+		detector secret.IBANDetector
+		input    string
+		want     string
+	}{
+		{
+			name:  "israel",
+			input: "iban IL150120690000003111111 paid",
+			want:  "iban IL****** paid",
+		},
+		{
+			name:  "germany",
+			input: "iban DE89370400440532013000 paid",
+			want:  "iban DE****** paid",
+		},
+		{
+			name:  "grouped",
+			input: "iban GB82 WEST 1234 5698 7654 32 paid",
+			want:  "iban GB****** paid",
+		},
+		{
+			name:     "mask all",
+			detector: secret.IBANDetector{MaskAll: true},
+			input:    "iban DE89370400440532013000 paid",
+			want:     "iban ****** paid",
+		},
+		{
+			name:  "bad checksum",
+			input: "iban DE89370400440532013001 paid",
+			want:  "iban DE89370400440532013001 paid",
+		},
+		{
+			name:  "wrong length for country",
+			input: "iban DE8937040044053201300 paid",
+			want:  "iban DE8937040044053201300 paid",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := secret.MaskOptions{Detectors: []secret.Detector{tt.detector}}
+			if got := secret.MaskSecretsOnStringWithOptions(tt.input, secret.BuiltinRules, opts); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package secret
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// MaskResult is masked text together with the findings behind the masks,
+// for log pipelines where the findings drive alerts and the masked text is
+// the safe payload. Its JSON form never contains the secrets.
+type MaskResult struct {
+	// Line is the 1-based line number of a per-line result; it is zero
+	// and omitted for a whole stream.
+	Line     int       `json:"line,omitempty"`
+	Masked   string    `json:"masked"`
+	Findings []Finding `json:"findings"`
+}
+
+// MaskSecretsWithFindings masks input and reports the secrets that were
+// masked. Rules skipped by opts, allowlisted secrets and secrets too weak
+// for opts are not reported.
+func MaskSecretsWithFindings(input string, rules []Rule, opts MaskOptions) MaskResult {
+	return MaskResult{
+		Masked:   MaskSecretsOnStringWithOptions(input, rules, opts),
+		Findings: append(make([]Finding, 0), maskedFindings(input, rules, opts)...),
+	}
+}
+
+// maskedFindings returns the findings in input of the rules not skipped by
+// opts, leaving out allowlisted secrets, placeholders left by earlier
+// masking and secrets too weak for opts.
+func maskedFindings(input string, rules []Rule, opts MaskOptions) []Finding {
+	active := filterRules(rules, func(rule Rule) bool { return !opts.skips(rule) })
+	var findings []Finding
+	for _, f := range FindSecrets(input, active) {
+		level, _ := ParseSeverity(f.Severity)
+		if !opts.allowed(f.Match) && !opts.placeholder(f.Match) && !opts.weak(level, f.Match) {
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+// MaskSecretsJSON reads all of r and writes a single MaskResult for it to w
+// as JSON.
+func MaskSecretsJSON(r io.Reader, w io.Writer, rules []Rule, opts MaskOptions) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return json.NewEncoder(w).Encode(MaskSecretsWithFindings(string(data), rules, opts))
+}
+
+// MaskSecretsJSONLines writes one MaskResult per line of r to w, as JSON
+// Lines. Line terminators are not part of Masked, and finding offsets count
+// from the beginning of r.
+func MaskSecretsJSONLines(r io.Reader, w io.Writer, rules []Rule, opts MaskOptions) error {
+	br := bufio.NewReader(r)
+	enc := json.NewEncoder(w)
+	offset := 0
+	for lineNo := 1; ; lineNo++ {
+		line, err := br.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		if line == "" {
+			return nil
+		}
+
+		content, _ := splitEOL(line)
+		result := MaskSecretsWithFindings(content, rules, opts)
+		result.Line = lineNo
+		for i := range result.Findings {
+			result.Findings[i].Line = lineNo
+			result.Findings[i].Start += offset
+			result.Findings[i].End += offset
+		}
+		if err := enc.Encode(result); err != nil {
+			return err
+		}
+		offset += len(line)
+
+		if err != nil {
+			return nil
+		}
+	}
+}
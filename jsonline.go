@@ -0,0 +1,95 @@
+package secret
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// maskJSONLine masks the string values of line, a JSON object or array,
+// and leaves its keys, structure and formatting untouched. Replacements are
+// escaped as JSON, so the result is valid JSON. It reports false if line is
+// not a valid JSON object or array.
+//
+// Each value is matched together with its key, written as "key": "value",
+// so that rules which need the key as context, such as
+// aws-secret-access-key, still find the secret; only the part of a match
+// that falls inside the value is masked.
+func maskJSONLine(line string, rules []Rule, opts MaskOptions) (string, bool) {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" || trimmed[0] != '{' && trimmed[0] != '[' || !json.Valid([]byte(trimmed)) {
+		return "", false
+	}
+
+	var sb strings.Builder
+	var key string
+	last := 0
+	for i := 0; i < len(line); i++ {
+		if line[i] != '"' {
+			continue
+		}
+		end := jsonStringEnd(line, i)
+		var s string
+		if err := json.Unmarshal([]byte(line[i:end]), &s); err != nil {
+			return "", false
+		}
+
+		next := end
+		for next < len(line) && strings.IndexByte(" \t\r\n", line[next]) >= 0 {
+			next++
+		}
+		if next < len(line) && line[next] == ':' {
+			key = s
+		} else if masked := maskJSONValue(key, s, rules, opts); masked != s {
+			sb.WriteString(line[last:i])
+			sb.WriteString(jsonQuote(masked))
+			last = end
+		}
+		i = end - 1
+	}
+	sb.WriteString(line[last:])
+	return sb.String(), true
+}
+
+// jsonStringEnd returns the index just past the closing quote of the JSON
+// string literal that starts at line[start].
+func jsonStringEnd(line string, start int) int {
+	for i := start + 1; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return len(line)
+}
+
+// maskJSONValue masks value, the string value of key, in the context of
+// its key.
+func maskJSONValue(key, value string, rules []Rule, opts MaskOptions) string {
+	prefix := `"` + key + `": "`
+	context := prefix + value + `"`
+	lo, hi := len(prefix), len(prefix)+len(value)
+
+	var spans []span
+	for _, sp := range ruleSpans(context, rules, opts) {
+		start, end := max(sp.start, lo), min(sp.end, hi)
+		if start < end {
+			spans = append(spans, span{start: start - lo, end: end - lo, rule: sp.rule, keep: sp.keep})
+		}
+	}
+	masked, protected := replaceSpans(value, spans, opts)
+	return applyDetectors(masked, protected, opts)
+}
+
+// jsonQuote returns s as a JSON string literal without escaping HTML
+// characters.
+func jsonQuote(s string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	// Encoding a string cannot fail.
+	_ = enc.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
@@ -0,0 +1,218 @@
+package secret
+
+import "strings"
+
+// keywordIndex finds the rules whose keywords occur in an input with a
+// single pass over it, instead of one strings.Contains per keyword. It runs
+// an Aho-Corasick automaton over the ASCII-lowercased keywords of all
+// rules; hits for keywords compared case-sensitively are confirmed against
+// the input itself.
+type keywordIndex struct {
+	// rules is the slice the index was built for.
+	rules []Rule
+	// always marks rules without keywords, which run on every input.
+	always []bool
+	// fallback marks rules with non-ASCII keywords, which are checked
+	// with hasKeyword instead.
+	fallback []bool
+	// hasFolded is set when some rule compares its keywords
+	// case-insensitively.
+	hasFolded bool
+
+	keywords []indexedKeyword
+	// ac finds the keywords, numbered as in keywords.
+	ac *ahoCorasick
+}
+
+// indexedKeyword is a keyword of a single rule.
+type indexedKeyword struct {
+	rule int
+	text string
+	fold bool
+}
+
+// newKeywordIndex builds the index for rules.
+func newKeywordIndex(rules []Rule) *keywordIndex {
+	idx := &keywordIndex{
+		rules:    rules,
+		always:   make([]bool, len(rules)),
+		fallback: make([]bool, len(rules)),
+	}
+	for i, rule := range rules {
+		if len(rule.Keywords) == 0 {
+			idx.always[i] = true
+			continue
+		}
+		fold := foldsKeywords(rule)
+		for _, kw := range rule.Keywords {
+			if kw == "" || !isASCII(kw) {
+				idx.fallback[i] = true
+				break
+			}
+		}
+		if idx.fallback[i] {
+			continue
+		}
+		idx.hasFolded = idx.hasFolded || fold
+		for _, kw := range rule.Keywords {
+			idx.keywords = append(idx.keywords, indexedKeyword{rule: i, text: kw, fold: fold})
+		}
+	}
+
+	texts := make([]string, len(idx.keywords))
+	for k, kw := range idx.keywords {
+		texts[k] = kw.text
+	}
+	idx.ac = newAhoCorasick(texts)
+	return idx
+}
+
+// indexes reports whether idx was built for rules.
+func (idx *keywordIndex) indexes(rules []Rule) bool {
+	return len(idx.rules) == len(rules) && (len(rules) == 0 || &idx.rules[0] == &rules[0])
+}
+
+// candidates reports for each rule whether it passes the keyword
+// pre-filter on input, with the same result as hasKeyword.
+func (idx *keywordIndex) candidates(input string) []bool {
+	run := make([]bool, len(idx.rules))
+	copy(run, idx.always)
+
+	// strings.ToLower maps some non-ASCII runes, such as the Kelvin sign,
+	// to ASCII letters, so folded keywords on non-ASCII input are left to
+	// hasKeyword.
+	foldFallback := idx.hasFolded && !isASCII(input)
+
+	idx.ac.scan(input, func(k, end int) {
+		kw := idx.keywords[k]
+		if run[kw.rule] || kw.fold && foldFallback {
+			return
+		}
+		if kw.fold || input[end-len(kw.text):end] == kw.text {
+			run[kw.rule] = true
+		}
+	})
+
+	var lowerInput string
+	for i, rule := range idx.rules {
+		if run[i] || !idx.fallback[i] && !(foldFallback && foldsKeywords(rule)) {
+			continue
+		}
+		if lowerInput == "" {
+			lowerInput = strings.ToLower(input)
+		}
+		run[i] = hasKeyword(input, lowerInput, rule)
+	}
+	return run
+}
+
+// ahoCorasick is an Aho-Corasick automaton over ASCII-lowercased patterns.
+// It reports every occurrence of every pattern in one pass over an input;
+// callers that need case-sensitive matches confirm them against the input.
+type ahoCorasick struct {
+	// class maps each ASCII-lowercased byte to its column in next; bytes
+	// that occur in no pattern map to column 0.
+	class    [256]uint8
+	nClasses int
+	// next is the transition table, nClasses entries per state.
+	next []int32
+	// out lists, per state, the patterns that end there.
+	out [][]int32
+}
+
+// newAhoCorasick builds the automaton for patterns, none of which may be
+// empty.
+func newAhoCorasick(patterns []string) *ahoCorasick {
+	ac := &ahoCorasick{}
+
+	// Give each byte that occurs in a lowercased pattern its own column.
+	ac.nClasses = 1
+	for _, p := range patterns {
+		for j := 0; j < len(p); j++ {
+			if b := lowerASCII(p[j]); ac.class[b] == 0 {
+				ac.class[b] = uint8(ac.nClasses)
+				ac.nClasses++
+			}
+		}
+	}
+
+	// Build the trie; -1 marks a missing transition.
+	ac.addState()
+	for k, p := range patterns {
+		state := 0
+		for j := 0; j < len(p); j++ {
+			c := int(ac.class[lowerASCII(p[j])])
+			if ac.next[state*ac.nClasses+c] < 0 {
+				ac.next[state*ac.nClasses+c] = int32(ac.addState())
+			}
+			state = int(ac.next[state*ac.nClasses+c])
+		}
+		ac.out[state] = append(ac.out[state], int32(k))
+	}
+
+	// Turn the trie into a DFA breadth first: a missing transition follows
+	// the failure link, and each state also emits the patterns of the
+	// state its failure link points to.
+	fail := make([]int, len(ac.out))
+	queue := []int{0}
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		for c := 0; c < ac.nClasses; c++ {
+			t := &ac.next[state*ac.nClasses+c]
+			switch {
+			case *t < 0 && state == 0:
+				*t = 0
+			case *t < 0:
+				*t = ac.next[fail[state]*ac.nClasses+c]
+			default:
+				child := int(*t)
+				if state != 0 {
+					fail[child] = int(ac.next[fail[state]*ac.nClasses+c])
+				}
+				ac.out[child] = append(ac.out[child], ac.out[fail[child]]...)
+				queue = append(queue, child)
+			}
+		}
+	}
+	return ac
+}
+
+// addState appends a state without transitions and returns its number.
+func (ac *ahoCorasick) addState() int {
+	for c := 0; c < ac.nClasses; c++ {
+		ac.next = append(ac.next, -1)
+	}
+	ac.out = append(ac.out, nil)
+	return len(ac.out) - 1
+}
+
+// scan calls found with the number of the pattern and the end offset of
+// each occurrence in input, ignoring ASCII case, in order of end offset.
+func (ac *ahoCorasick) scan(input string, found func(pattern, end int)) {
+	state := 0
+	for i := 0; i < len(input); i++ {
+		state = int(ac.next[state*ac.nClasses+int(ac.class[lowerASCII(input[i])])])
+		for _, k := range ac.out[state] {
+			found(int(k), i+1)
+		}
+	}
+}
+
+// lowerASCII lowercases an ASCII letter and returns any other byte as is.
+func lowerASCII(b byte) byte {
+	if 'A' <= b && b <= 'Z' {
+		return b + 'a' - 'A'
+	}
+	return b
+}
+
+// isASCII reports whether s consists of ASCII bytes only.
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
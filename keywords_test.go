@@ -0,0 +1,67 @@
+package secret_test
+
+import (
+	"os"
+	"regexp"
+	"secret"
+	"strings"
+	"testing"
+)
+
+// TestKeywordIndexMatchesContains checks that masking through a Registry,
+// which pre-filters rules with its keyword index, gives the same output as
+// checking each rule's keywords separately.
+func TestKeywordIndexMatchesContains(t *testing.T) {
+	rules := append([]secret.Rule{
+		{ID: "exact", Regex: regexp.MustCompile(`Tok_[0-9]{6}`), Keywords: []string{"Tok_"}},
+		{ID: "folded", Regex: regexp.MustCompile(`(?i)kelvin=[0-9]{6}`), Keywords: []string{"kelvin"}},
+		{ID: "non-ascii", Regex: regexp.MustCompile(`clé=[0-9]{6}`), Keywords: []string{"clé"}},
+	}, secret.BuiltinRules...)
+	registry := secret.NewRegistry(rules)
+
+	data, err := os.ReadFile(benchCorpus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := append(strings.Split(string(data), "\n"),
+		"Tok_123456 tok_123456 TOK_123456",
+		"KELVIN=123456 kelvin=654321",
+		"\u212Aelvin=123456", // Kelvin sign, which strings.ToLower maps to k
+		"clé=123456 CLÉ=123456",
+		"AWS_SECRET_ACCESS_KEY=wJalrXUtnFEMI/K7MDENG/bPxRfiCYEXAMPLEKEY",
+	)
+
+	for _, line := range lines {
+		want := secret.MaskSecretsOnString(line, rules)
+		if got := registry.Mask(line, secret.MaskOptions{}); got != want {
+			t.Errorf("Registry.Mask(%q) = %q, want %q", line, got, want)
+		}
+	}
+}
+
+// The benchmarks below compare the keyword pre-filter on a line without
+// secrets, the common case, with and without the index a Registry builds.
+
+const cleanLogLine = `2024-05-24T08:50:18Z INFO step 14/32 : RUN npm ci --no-audit --prefer-offline && npm run build -- --mode=production (took 41.2s, cache hit ratio 0.83)`
+
+func BenchmarkKeywordIndexBuild(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		secret.NewRegistry(secret.BuiltinRules)
+	}
+}
+
+func BenchmarkCleanLineContains(b *testing.B) {
+	b.SetBytes(int64(len(cleanLogLine)))
+	for i := 0; i < b.N; i++ {
+		secret.MaskSecretsOnString(cleanLogLine, secret.BuiltinRules)
+	}
+}
+
+func BenchmarkCleanLineIndexed(b *testing.B) {
+	registry := secret.NewRegistry(secret.BuiltinRules)
+	b.SetBytes(int64(len(cleanLogLine)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		registry.Mask(cleanLogLine, secret.MaskOptions{})
+	}
+}
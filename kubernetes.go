@@ -0,0 +1,131 @@
+package secret
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MaskKubernetesManifests masks YAML Kubernetes manifests such as the output
+// of kubectl get secret -o yaml. Every value under data and stringData of a
+// Secret is masked whether or not a rule matches it, since those values are
+// secrets by definition; Secrets nested in a List are found too. All other
+// string values are masked with rules as usual. Keys, metadata and
+// comments are kept, though the YAML is re-encoded with two-space
+// indentation.
+func MaskKubernetesManifests(manifests []byte, rules []Rule, opts MaskOptions) ([]byte, error) {
+	return maskYAMLDocuments(manifests, rules, nil, opts)
+}
+
+// DefaultSensitiveYAMLKey matches the mapping keys whose values MaskYAML
+// masks whatever they contain, such as password, apiKey or
+// db_connection_string. Only the end of the key counts, so secretName,
+// which usually holds a reference, is left alone.
+var DefaultSensitiveYAMLKey = regexp.MustCompile(`(?i)(secret|token|password|passwd|pwd|api_?key|access_?key|private_?key|credentials?|auth|dsn|connection_?string)$`)
+
+// yamlReferenceKey matches keys that name a Secret rather than hold one,
+// such as Helm's existingSecret, so that MaskYAML keeps their values even
+// though the key ends like a sensitive one.
+var yamlReferenceKey = regexp.MustCompile(`(?i)^existing|secret_?ref$|name$`)
+
+// MaskYAML masks YAML documents such as Helm values files or multi-document
+// manifests separated by ---. Each document is parsed and its string
+// values are masked with rules, except that the whole value of a key
+// matching DefaultSensitiveYAMLKey is masked whatever its format, as are
+// the values of Kubernetes Secrets. Keys that name a Secret, such as
+// existingSecret, are not masked. The output is valid YAML; keys and
+// comments are kept, though it is re-encoded with two-space indentation.
+func MaskYAML(data []byte, rules []Rule, opts MaskOptions) ([]byte, error) {
+	return maskYAMLDocuments(data, rules, DefaultSensitiveYAMLKey, opts)
+}
+
+// maskYAMLDocuments masks each YAML document in data. The values of keys
+// matching keys, if not nil, are masked whole.
+func maskYAMLDocuments(data []byte, rules []Rule, keys *regexp.Regexp, opts MaskOptions) ([]byte, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+
+	for {
+		var doc yaml.Node
+		if err := dec.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		maskManifestNode(&doc, rules, keys, opts)
+		if err := enc.Encode(&doc); err != nil {
+			return nil, err
+		}
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// maskManifestNode masks the string values below node, and the scalar
+// values of the keys matching keys whole.
+func maskManifestNode(node *yaml.Node, rules []Rule, keys *regexp.Regexp, opts MaskOptions) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if node.Tag == "!!str" {
+			node.Value = MaskSecretsOnStringWithOptions(node.Value, rules, opts)
+		}
+		return
+	case yaml.MappingNode:
+		secret := isKubernetesSecret(node)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if secret && (key.Value == "data" || key.Value == "stringData") && value.Kind == yaml.MappingNode {
+				maskSecretData(value, "kubernetes-secret", opts)
+				continue
+			}
+			if keys != nil && value.Kind == yaml.ScalarNode && keys.MatchString(key.Value) && !yamlReferenceKey.MatchString(key.Value) {
+				maskSecretValue(value, "yaml-secret-key", opts)
+				continue
+			}
+			maskManifestNode(value, rules, keys, opts)
+		}
+		return
+	}
+	for _, child := range node.Content {
+		maskManifestNode(child, rules, keys, opts)
+	}
+}
+
+// isKubernetesSecret reports whether a mapping is a manifest of kind Secret.
+func isKubernetesSecret(node *yaml.Node) bool {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == "kind" {
+			return node.Content[i+1].Value == "Secret"
+		}
+	}
+	return false
+}
+
+// maskSecretData replaces every value of a Secret's data or stringData,
+// recording them under id.
+func maskSecretData(data *yaml.Node, id string, opts MaskOptions) {
+	for i := 1; i < len(data.Content); i += 2 {
+		maskSecretValue(data.Content[i], id, opts)
+	}
+}
+
+// maskSecretValue replaces a scalar value whole and records it under id.
+// Empty, null, boolean and allowlisted values are kept.
+func maskSecretValue(value *yaml.Node, id string, opts MaskOptions) {
+	if value.Kind != yaml.ScalarNode || value.Value == "" || value.Tag == "!!null" || value.Tag == "!!bool" || opts.placeholder(value.Value) || opts.allowed(value.Value) {
+		return
+	}
+	opts.record(id, SeverityHigh)
+	value.Value = opts.replacement(id, value.Value)
+	// A masked multi-line value no longer needs a block scalar.
+	value.Style = 0
+	value.Tag = "!!str"
+}
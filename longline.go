@@ -0,0 +1,69 @@
+package secret
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// LongLinePolicy decides how the streaming masker handles a line longer
+// than StreamOptions.MaxLineLength.
+type LongLinePolicy int
+
+const (
+	// LongLineMask replaces the whole line with the mask. It is the
+	// default because a line that is not scanned as a whole may hold a
+	// secret. PreserveLength is not honoured, as the line is never held
+	// in memory.
+	LongLineMask LongLinePolicy = iota
+	// LongLineWindows masks the line in windows of MaxLineLength bytes,
+	// carrying the end of each window over into the next so that a secret
+	// straddling two of them is still found.
+	LongLineWindows
+	// LongLineTruncate masks and keeps the first MaxLineLength bytes of the
+	// line and replaces the rest with a marker giving the line's length.
+	LongLineTruncate
+)
+
+// longLine tracks an over-long line whose head has been written under
+// LongLineMask or LongLineTruncate while the rest of it is skipped.
+type longLine struct {
+	active bool
+	// length is the number of bytes of the line seen so far, excluding
+	// the terminator.
+	length int
+}
+
+// writeLongLineHead starts an over-long line whose first token has the
+// given content. Under LongLineTruncate the content, which extends
+// maxSecretLen bytes past the cut, is masked before it is cut to max bytes
+// so that the cut does not expose the start of a secret.
+func (lw *lineWriter) writeLongLineHead(content string, policy LongLinePolicy, max int) error {
+	if policy != LongLineTruncate {
+		return nil
+	}
+	masked := lw.registry.Mask(content, lw.opts)
+	if len(masked) > max {
+		cut := max
+		for cut > 0 && !utf8.RuneStart(masked[cut]) {
+			cut--
+		}
+		masked = masked[:cut]
+	}
+	_, err := lw.w.Write([]byte(masked))
+	return err
+}
+
+// writeLongLineTail ends an over-long line of length bytes with its
+// terminator eol.
+func (lw *lineWriter) writeLongLineTail(length int, eol string, policy LongLinePolicy) error {
+	var text string
+	if policy == LongLineTruncate {
+		text = fmt.Sprintf("...[truncated line of %d bytes]", length)
+	} else {
+		opts := lw.opts
+		opts.PreserveLength = false
+		text = opts.mask("")
+	}
+	_, err := lw.w.Write([]byte(text + eol))
+	return err
+}
@@ -0,0 +1,74 @@
+package secret
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// Manifest summarises the secrets masked in a run by rule, as evidence for
+// an audit of what was scrubbed. It holds counts and line numbers only,
+// never the secrets. Add findings to it from StreamOptions.OnFinding, then
+// write it with WriteJSON or WriteCSV. Like OnFinding, it counts the
+// secrets masked by rules; secrets found by detectors are not included. A
+// Manifest is not safe for concurrent use.
+type Manifest struct {
+	// File names the input, if it was read from a file.
+	File string `json:"file,omitempty"`
+	// Total is the number of secrets added.
+	Total int `json:"total"`
+	// Rules has an entry per rule that masked a secret, ordered by rule ID.
+	Rules []ManifestRule `json:"rules"`
+}
+
+// ManifestRule is the Manifest entry of one rule.
+type ManifestRule struct {
+	RuleID   string `json:"ruleId"`
+	Severity string `json:"severity"`
+	Count    int    `json:"count"`
+	// FirstLine and LastLine are the lines of the first and the last
+	// secret the rule masked.
+	FirstLine int `json:"firstLine"`
+	LastLine  int `json:"lastLine"`
+}
+
+// Add counts the finding under its rule. Only its rule, severity and line
+// are used.
+func (m *Manifest) Add(f Finding) {
+	m.Total++
+	i := sort.Search(len(m.Rules), func(i int) bool { return m.Rules[i].RuleID >= f.RuleID })
+	if i == len(m.Rules) || m.Rules[i].RuleID != f.RuleID {
+		m.Rules = append(m.Rules, ManifestRule{})
+		copy(m.Rules[i+1:], m.Rules[i:])
+		m.Rules[i] = ManifestRule{RuleID: f.RuleID, Severity: f.Severity, FirstLine: f.Line}
+	}
+	r := &m.Rules[i]
+	r.Count++
+	r.FirstLine = min(r.FirstLine, f.Line)
+	r.LastLine = max(r.LastLine, f.Line)
+}
+
+// WriteJSON writes the manifest as an indented JSON object.
+func (m *Manifest) WriteJSON(w io.Writer) error {
+	out := *m
+	if out.Rules == nil {
+		out.Rules = []ManifestRule{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// WriteCSV writes the manifest as CSV with a header row and a row per rule:
+// file, rule_id, severity, count, first_line and last_line.
+func (m *Manifest) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"file", "rule_id", "severity", "count", "first_line", "last_line"})
+	for _, r := range m.Rules {
+		cw.Write([]string{m.File, r.RuleID, r.Severity, strconv.Itoa(r.Count), strconv.Itoa(r.FirstLine), strconv.Itoa(r.LastLine)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,580 @@
+package secret
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// DefaultReplacement is the text substituted for a secret when no options
+// are given.
+const DefaultReplacement = "******"
+
+// maskRune is repeated once per secret character when masking preserves
+// length.
+const maskRune = '*'
+
+// MaskOptions controls how matched secrets are replaced.
+type MaskOptions struct {
+	// Replacement is the literal text substituted for each secret.
+	// When empty, DefaultReplacement is used.
+	Replacement string
+	// PreserveLength replaces each secret character with a single mask
+	// rune so the masked output keeps the original width and fixed-width
+	// or column-aligned logs stay aligned. Replacement and the rules' own
+	// replacements are ignored; RevealLast still applies. Repeat markers,
+	// TokenKey and HashPrefix tokens and fingerprints change the width.
+	PreserveLength bool
+	// RevealLast keeps the trailing RevealLast characters of each secret
+	// visible so leaked credentials can be correlated. Zero masks the whole
+	// secret, as does a value not smaller than the secret's length.
+	RevealLast int
+	// TokenKey, when set, replaces each secret with a pseudonymous token
+	// derived from an HMAC of the secret under this key, e.g.
+	// SECRET_3f9a0c12b7e4. Equal secrets get equal tokens, so repeated use
+	// of one credential can be correlated. It takes precedence over the
+	// other replacement options.
+	TokenKey []byte
+	// HashPrefix, when positive, replaces each secret with that many hex
+	// digits of its unkeyed SHA-256, e.g. sha256:ab12cd, so the same secret
+	// can be correlated across systems or looked up in breach databases.
+	// Short secrets can be brute-forced from their hash; prefer TokenKey
+	// when the output may be public. TokenKey takes precedence.
+	HashPrefix int
+	// FingerprintSalt, when not nil, replaces each secret with a
+	// fixed-width fingerprint such as ‹secret:ab12cd›, six hex digits of
+	// the SHA-256 of the salt and the secret. Logs masked with the same
+	// salt diff cleanly where the same value leaked and show where it
+	// changed. An empty, non-nil salt is allowed. TokenKey and HashPrefix
+	// take precedence.
+	FingerprintSalt []byte
+	// Masker, when set, decides the replacement of every secret and takes
+	// precedence over all the options above and the rules' replacements.
+	// Repeat markers are still appended.
+	Masker Masker
+	// Repeats, when set, tracks the secrets masked so far and marks each
+	// repeated occurrence of a secret with its repeat count, e.g. ******(1)
+	// for the second, so readers can see the same value recurring. Share
+	// one RepeatCounter across the calls of a masking session.
+	Repeats *RepeatCounter
+	// Entropy, when set, additionally masks high-entropy tokens that none
+	// of the rules matched.
+	Entropy *EntropyDetector
+	// Detectors are applied after the rules, in order.
+	Detectors []Detector
+	// Allowlist holds patterns for known false positives such as test
+	// fixtures. A secret matched by any of them is left unmasked. The
+	// patterns are checked against the secret itself, not the whole line.
+	Allowlist []*regexp.Regexp
+	// AllowlistValues holds literal secrets that are left unmasked.
+	AllowlistValues []string
+	// MinSeverity skips rules whose severity is below it. The zero value
+	// applies every rule.
+	MinSeverity Severity
+	// SkipPublic skips rules marked PublicByDesign.
+	SkipPublic bool
+	// MinLength, when positive, leaves secrets shorter than MinLength
+	// bytes unmasked if they were matched by a rule of LOW or unknown
+	// severity, whose patterns are generic enough to match ordinary words.
+	MinLength int
+	// MinCharClasses, when positive, leaves secrets of LOW or unknown
+	// severity unmasked unless they mix at least that many of the classes
+	// lowercase letters, uppercase letters, digits and other characters.
+	// With 2, helloworldfoobar is left alone while h3lloW0rld is masked.
+	MinCharClasses int
+	// ANSI decides how ANSI escape sequences in the input are handled.
+	ANSI ANSIMode
+	// LineTimeout bounds the time spent masking a single input. Zero means
+	// no limit. See TimeoutPolicy for what is emitted when it is exceeded.
+	LineTimeout time.Duration
+	// TimeoutPolicy decides the output for an input whose masking timed out.
+	TimeoutPolicy TimeoutPolicy
+	// OnTimeout, when set, is called for every input whose masking timed out.
+	OnTimeout func(TimeoutEvent)
+	// Metrics, when set, is told about every masked secret and input.
+	Metrics Metrics
+	// SlowRules, when set, times every rule and skips the rules it has
+	// disabled for being consistently slow.
+	SlowRules *SlowRuleGuard
+	// Sampling, when set, limits the full scan to the lines it selects;
+	// other lines are only checked by rules with keywords. See Sampling
+	// for what this gives up.
+	Sampling *Sampling
+
+	// stats, when set, counts the masked secrets.
+	stats *Stats
+	// keywords, when built for the rules being applied, replaces the
+	// per-rule keyword check.
+	keywords *keywordIndex
+	// gatedOnly skips the rules without keywords, set for inputs that
+	// Sampling leaves out of the full scan.
+	gatedOnly bool
+	// cutBefore and cutAfter are set when detectors see a piece of the
+	// input that a secret masked by a rule precedes or follows, so the
+	// first or last line of the piece is not a whole line.
+	cutBefore, cutAfter bool
+}
+
+// record counts a secret masked by the rule or detector id.
+func (o MaskOptions) record(id string, level Severity) {
+	if o.stats != nil {
+		o.stats.add(id, level)
+	}
+	if o.Metrics != nil {
+		o.Metrics.SecretMasked(id, level)
+	}
+}
+
+// skips reports whether rule is disabled by the options.
+func (o MaskOptions) skips(rule Rule) bool {
+	return rule.SeverityLevel() < o.MinSeverity || (o.SkipPublic && rule.PublicByDesign)
+}
+
+// weak reports whether secret, matched by a rule of the given severity, is
+// too short or too uniform to be masked under the options.
+func (o MaskOptions) weak(level Severity, secret string) bool {
+	if level > SeverityLow {
+		return false
+	}
+	if o.MinLength > 0 && len(secret) < o.MinLength {
+		return true
+	}
+	return o.MinCharClasses > 0 && charClasses(secret) < o.MinCharClasses
+}
+
+// charClasses returns how many of the classes lowercase ASCII letters,
+// uppercase ASCII letters, digits and other characters occur in s.
+func charClasses(s string) int {
+	var lower, upper, digit, other int
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case 'a' <= c && c <= 'z':
+			lower = 1
+		case 'A' <= c && c <= 'Z':
+			upper = 1
+		case '0' <= c && c <= '9':
+			digit = 1
+		default:
+			other = 1
+		}
+	}
+	return lower + upper + digit + other
+}
+
+// allowed reports whether secret is on the allowlist.
+func (o MaskOptions) allowed(secret string) bool {
+	for _, v := range o.AllowlistValues {
+		if secret == v {
+			return true
+		}
+	}
+	for _, re := range o.Allowlist {
+		if re.MatchString(secret) {
+			return true
+		}
+	}
+	return false
+}
+
+// placeholderRegex matches the replacements made with TokenKey, HashPrefix
+// and FingerprintSalt.
+var placeholderRegex = regexp.MustCompile(`^(?:` + tokenPrefix + `[0-9a-f]+|` + hashPrefix + `[0-9a-f]+|‹secret:[0-9a-f]+›)$`)
+
+// placeholder reports whether value is a replacement made by masking, such
+// as ****** or SECRET_3f9a0c12b7e4, possibly with a repeat marker. Rules
+// whose secret group takes any value, like URL passwords, would otherwise
+// match their own output, so masking masked text again would replace the
+// placeholder and report it as a new finding.
+func (o MaskOptions) placeholder(value string) bool {
+	if i := strings.LastIndexByte(value, '('); i > 0 && strings.HasSuffix(value, ")") {
+		if _, err := strconv.Atoi(value[i+1 : len(value)-1]); err == nil {
+			value = value[:i]
+		}
+	}
+	if placeholderRegex.MatchString(value) {
+		return true
+	}
+	if cut := revealCut(value, o.RevealLast); cut > 0 {
+		value = value[:cut]
+	}
+	return strings.Trim(value, string(maskRune)) == "" || value == DefaultReplacement || value == o.Replacement
+}
+
+// replacement returns the text that should stand in for secret, found by
+// the detector or rule id.
+func (o MaskOptions) replacement(id, secret string) string {
+	return o.ruleReplacement(&Rule{ID: id}, secret)
+}
+
+// revealCut returns the byte offset at which the last n characters of
+// secret start, or 0 if secret has no more than n characters. Cutting on a
+// rune boundary keeps multi-byte characters whole; invalid UTF-8 bytes count
+// as one character each.
+func revealCut(secret string, n int) int {
+	cut := len(secret)
+	for ; n > 0 && cut > 0; n-- {
+		_, size := utf8.DecodeLastRuneInString(secret[:cut])
+		cut -= size
+	}
+	return cut
+}
+
+// mask returns the text that hides secret entirely without consulting the
+// Masker, for text that is not a single secret, such as a whole line.
+func (o MaskOptions) mask(secret string) string {
+	if o.PreserveLength {
+		return strings.Repeat(string(maskRune), utf8.RuneCountInString(secret))
+	}
+	if o.Replacement == "" {
+		return DefaultReplacement
+	}
+	return o.Replacement
+}
+
+// MaskSecretsOnString masks the secrets that rules find in input and
+// returns the result.
+func MaskSecretsOnString(input string, rules []Rule) string {
+	return MaskSecretsOnStringWithOptions(input, rules, MaskOptions{})
+}
+
+// MaskSecretsOnStringWithOptions is like MaskSecretsOnString but replaces
+// secrets according to opts.
+func MaskSecretsOnStringWithOptions(input string, rules []Rule, opts MaskOptions) string {
+	if opts.Metrics != nil {
+		defer func(start time.Time) {
+			opts.Metrics.InputMasked(len(input), time.Since(start))
+		}(time.Now())
+	}
+	if opts.LineTimeout > 0 {
+		return maskWithTimeout(input, rules, opts)
+	}
+	return maskSecrets(input, rules, opts)
+}
+
+// maskSecrets applies the rules, the optional entropy detector and the
+// detectors to input.
+func maskSecrets(input string, rules []Rule, opts MaskOptions) string {
+	if opts.Sampling != nil && !opts.Sampling.fullScan(input, rules, opts) {
+		opts.Entropy, opts.Detectors, opts.gatedOnly = nil, nil, true
+	}
+	var maskedInput string
+	var protected []span
+	switch {
+	case opts.ANSI == ANSIStrip:
+		maskedInput, protected = maskRules(StripANSI(input), rules, opts)
+	case opts.ANSI == ANSIPreserve && strings.Contains(input, "\x1b"):
+		maskedInput, protected = maskANSI(input, rules, opts)
+	default:
+		maskedInput, protected = maskRules(input, rules, opts)
+	}
+	return applyDetectors(maskedInput, protected, opts)
+}
+
+// maskRules applies the rules that are not skipped by opts to input. All
+// rules are matched against the original input and overlapping secrets are
+// replaced as one, so the output does not depend on the order of the rules.
+// It also returns the spans of the replacements within the output.
+func maskRules(input string, rules []Rule, opts MaskOptions) (string, []span) {
+	return replaceSpans(input, ruleSpans(input, rules, opts), opts)
+}
+
+// replaceSpans replaces the secrets at the sorted, disjoint spans of input
+// and returns the result with the spans of the replacements within it.
+func replaceSpans(input string, spans []span, opts MaskOptions) (string, []span) {
+	if spans == nil {
+		return input, nil
+	}
+
+	var sb strings.Builder
+	replaced := make([]span, 0, len(spans))
+	last := 0
+	for _, sp := range spans {
+		sb.WriteString(input[last:sp.start])
+		start := sb.Len()
+		if sp.keep {
+			sb.WriteString(input[sp.start:sp.end])
+		} else {
+			sb.WriteString(opts.ruleReplacement(sp.rule, input[sp.start:sp.end]))
+		}
+		replaced = append(replaced, span{start: start, end: sb.Len()})
+		last = sp.end
+	}
+	sb.WriteString(input[last:])
+	return sb.String(), replaced
+}
+
+// applyDetectors runs the optional entropy detector and the detectors on
+// input, which rules have already masked. Each stretch of text between the
+// protected spans, the rules' replacements, is passed to the detectors on
+// its own, so a detector can neither match inside a replacement nor across
+// one.
+func applyDetectors(input string, protected []span, opts MaskOptions) string {
+	if opts.Entropy == nil && len(opts.Detectors) == 0 {
+		return input
+	}
+
+	var sb strings.Builder
+	last := 0
+	for i, sp := range append(protected, span{start: len(input), end: len(input)}) {
+		piece := opts
+		piece.cutBefore, piece.cutAfter = i > 0, i < len(protected)
+		sb.WriteString(detect(input[last:sp.start], piece))
+		sb.WriteString(input[sp.start:sp.end])
+		last = sp.end
+	}
+	return sb.String()
+}
+
+// detect applies the optional entropy detector and the detectors to input.
+func detect(input string, opts MaskOptions) string {
+	if input == "" {
+		return input
+	}
+	if opts.Entropy != nil {
+		input = opts.Entropy.Mask(input, opts)
+	}
+	for _, d := range opts.Detectors {
+		input = d.Mask(input, opts)
+	}
+	return input
+}
+
+// ruleSpans returns the secrets in input matched by the rules that are not
+// skipped by opts, excluding allowlisted ones, as sorted spans with
+// overlapping and adjacent spans merged. Matches that are placeholders
+// from earlier masking become keep spans, so masking is idempotent. Each
+// secret is counted in the options' stats under the rule that matched it,
+// and each span refers to that rule.
+func ruleSpans(input string, rules []Rule, opts MaskOptions) []span {
+	var spans []span
+	var run []bool
+	var lowerInput string
+	if opts.keywords != nil && opts.keywords.indexes(rules) {
+		run = opts.keywords.candidates(input)
+	} else {
+		lowerInput = strings.ToLower(input)
+	}
+	for i := range rules {
+		rule := &rules[i]
+		if opts.skips(*rule) || opts.gatedOnly && len(rule.Keywords) == 0 {
+			continue
+		}
+		if run != nil && !run[i] || run == nil && !hasKeyword(input, lowerInput, *rule) {
+			continue
+		}
+		if opts.SlowRules != nil && opts.SlowRules.disabled(rule.ID) {
+			continue
+		}
+		for _, sp := range opts.timedSecretSpans(input, rule) {
+			secret := input[sp.start:sp.end]
+			if opts.placeholder(secret) {
+				spans = append(spans, span{start: sp.start, end: sp.end, keep: true})
+				continue
+			}
+			if opts.allowed(secret) || opts.weak(rule.SeverityLevel(), secret) {
+				continue
+			}
+			opts.record(rule.ID, rule.SeverityLevel())
+			sp.rule = rule
+			spans = append(spans, sp)
+		}
+	}
+	return mergeSpans(spans)
+}
+
+// timedSecretSpans is secretSpans timed by the options' SlowRuleGuard.
+func (o MaskOptions) timedSecretSpans(input string, rule *Rule) []span {
+	if o.SlowRules == nil {
+		return secretSpans(input, *rule)
+	}
+	start := time.Now()
+	spans := secretSpans(input, *rule)
+	o.SlowRules.observe(rule.ID, time.Since(start))
+	return spans
+}
+
+// mergeSpans sorts spans and joins those that overlap or touch. A joined
+// span takes the rule with the highest Priority among its spans; of equal
+// priorities, the rule of the span that starts first, and of spans starting
+// at the same offset, the one whose rule outranks the others.
+func mergeSpans(spans []span) []span {
+	if len(spans) < 2 {
+		return spans
+	}
+	sort.SliceStable(spans, func(i, j int) bool {
+		if spans[i].start != spans[j].start {
+			return spans[i].start < spans[j].start
+		}
+		return precedes(spans[i].rule, spans[j].rule)
+	})
+
+	merged := spans[:1]
+	for _, sp := range spans[1:] {
+		last := &merged[len(merged)-1]
+		if sp.start <= last.end {
+			last.end = max(last.end, sp.end)
+			if last.keep && !sp.keep {
+				last.rule, last.keep = sp.rule, false
+			} else if !sp.keep && rulePriority(sp.rule) > rulePriority(last.rule) {
+				last.rule = sp.rule
+			}
+			continue
+		}
+		merged = append(merged, sp)
+	}
+	return merged
+}
+
+// precedes reports whether rule a masks a secret in place of rule b when
+// their matches start at the same offset: by Priority, then by outranks.
+func precedes(a, b *Rule) bool {
+	if pa, pb := rulePriority(a), rulePriority(b); pa != pb {
+		return pa > pb
+	}
+	return outranks(a, b)
+}
+
+// rulePriority returns the Priority of rule, or zero without a rule.
+func rulePriority(rule *Rule) int {
+	if rule == nil {
+		return 0
+	}
+	return rule.Priority
+}
+
+// Detector masks secrets that a single regex cannot describe, for example
+// because a match must also pass a checksum.
+type Detector interface {
+	// Mask returns input with the detected secrets replaced according to opts.
+	Mask(input string, opts MaskOptions) string
+}
+
+// span is a half-open byte range [start, end) within a string.
+type span struct {
+	start, end int
+	// rule is the rule that matched the span, if any.
+	rule *Rule
+	// keep is set when the span is a placeholder left by earlier masking.
+	// It is written unchanged and not counted, but still protected from
+	// the detectors like a replacement.
+	keep bool
+}
+
+// secretSpans returns the byte ranges of the secrets matched by rule, in
+// order. If the rule names a secret group that took part in the match, the
+// span covers only that group, so the key name and separator around the
+// secret stay readable; otherwise it covers the whole match. Secrets below
+// the rule's minimum entropy, and those its Validate func rejects, are left
+// out.
+func secretSpans(input string, rule Rule) []span {
+	var spans []span
+	for _, m := range secretMatches(input, rule) {
+		spans = append(spans, m.secret)
+	}
+	return spans
+}
+
+// secretMatch is a rule match and the secret within it.
+type secretMatch struct {
+	match, secret span
+}
+
+// secretMatches is like secretSpans but also returns the whole match around
+// each secret.
+func secretMatches(input string, rule Rule) []secretMatch {
+	group := 0
+	if rule.SecretGroupName != "" {
+		if i := rule.Regex.SubexpIndex(rule.SecretGroupName); i > 0 {
+			group = i
+		}
+	}
+
+	var matches []secretMatch
+	for _, m := range findSubmatchIndexes(input, rule, group) {
+		start, end := m[2*group], m[2*group+1]
+		if start < 0 || rule.Entropy > 0 && ShannonEntropy(input[start:end]) < rule.Entropy {
+			continue
+		}
+		if rule.Validate != nil && !rule.Validate(input[start:end]) {
+			continue
+		}
+		matches = append(matches, secretMatch{match: span{start: m[0], end: m[1]}, secret: span{start: start, end: end}})
+	}
+	return matches
+}
+
+// findSubmatchIndexes returns the submatch indexes of every match of the
+// rule's regex, as FindAllStringSubmatchIndex does, unless the rule resumes
+// matching after the secret group. Matching then restarts at the end of
+// each secret, so the matches may overlap in their trailing context.
+func findSubmatchIndexes(input string, rule Rule, group int) [][]int {
+	if !rule.resumeAfterSecret || group == 0 {
+		return rule.Regex.FindAllStringSubmatchIndex(input, -1)
+	}
+	var all [][]int
+	for pos := 0; pos < len(input); {
+		m := rule.Regex.FindStringSubmatchIndex(input[pos:])
+		if m == nil {
+			break
+		}
+		for i := range m {
+			if m[i] >= 0 {
+				m[i] += pos
+			}
+		}
+		all = append(all, m)
+		next := m[2*group+1]
+		if next <= pos || m[2*group] < 0 {
+			next = max(m[1], pos+1)
+		}
+		pos = next
+	}
+	return all
+}
+
+// matchSpans returns the byte ranges of every whole match of the rules in
+// input, including any context around a rule's secret group.
+func matchSpans(input string, rules []Rule) []span {
+	var spans []span
+	lowerInput := strings.ToLower(input)
+	for _, rule := range rules {
+		if !hasKeyword(input, lowerInput, rule) {
+			continue
+		}
+		for _, m := range rule.Regex.FindAllStringIndex(input, -1) {
+			spans = append(spans, span{start: m[0], end: m[1]})
+		}
+	}
+	return spans
+}
+
+// foldsKeywords reports whether the rule's keywords are compared
+// case-insensitively.
+func foldsKeywords(rule Rule) bool {
+	return rule.foldKeywords || strings.Contains(rule.Regex.String(), "(?i")
+}
+
+// hasKeyword reports whether the input contains at least one of the rule's
+// keywords, which is a cheap precondition for the regex being able to match.
+// Rules without keywords always run. Keywords are compared case-insensitively
+// when the rule's regex enables case-insensitive matching anywhere, with
+// (?i) or (?i:...), and always for rules imported from gitleaks.
+func hasKeyword(input, lowerInput string, rule Rule) bool {
+	if len(rule.Keywords) == 0 {
+		return true
+	}
+	caseInsensitive := foldsKeywords(rule)
+	for _, keyword := range rule.Keywords {
+		if caseInsensitive {
+			if strings.Contains(lowerInput, strings.ToLower(keyword)) {
+				return true
+			}
+		} else if strings.Contains(input, keyword) {
+			return true
+		}
+	}
+	return false
+}
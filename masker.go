@@ -0,0 +1,142 @@
+package secret
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Masker decides the text that stands in for a secret. match is the secret
+// and rule the rule that found it; a secret found by a detector comes with
+// a rule that only has the detector's ID, e.g. "credit-card". Set
+// MaskOptions.Masker to use a custom Masker, for example one that stores
+// the secret in a vault and returns a reference to it.
+type Masker interface {
+	Mask(match string, rule Rule) string
+}
+
+// FixedMasker replaces every secret with the same text, DefaultReplacement
+// when Replacement is empty. It is the default.
+type FixedMasker struct {
+	Replacement string
+}
+
+// Mask returns the fixed replacement, whatever the secret.
+func (m FixedMasker) Mask(string, Rule) string {
+	if m.Replacement == "" {
+		return DefaultReplacement
+	}
+	return m.Replacement
+}
+
+// LengthMasker replaces each character of a secret with *, keeping the
+// width of the output, as MaskOptions.PreserveLength does.
+type LengthMasker struct{}
+
+// Mask returns one * per character of match.
+func (LengthMasker) Mask(match string, _ Rule) string {
+	return strings.Repeat(string(maskRune), utf8.RuneCountInString(match))
+}
+
+// RevealMasker keeps the last Last characters of a secret and masks the rest
+// with Masker, or with a FixedMasker when Masker is nil, as
+// MaskOptions.RevealLast does. A secret no longer than Last is masked whole.
+type RevealMasker struct {
+	Last   int
+	Masker Masker
+}
+
+// Mask masks match with Masker except for its last Last characters.
+func (m RevealMasker) Mask(match string, rule Rule) string {
+	inner := m.Masker
+	if inner == nil {
+		inner = FixedMasker{}
+	}
+	if cut := revealCut(match, m.Last); cut > 0 {
+		return inner.Mask(match[:cut], rule) + match[cut:]
+	}
+	return inner.Mask(match, rule)
+}
+
+// TokenMasker replaces a secret with a pseudonymous token derived from an
+// HMAC of it under Key, as MaskOptions.TokenKey does.
+type TokenMasker struct {
+	Key []byte
+}
+
+// Mask returns the token of match under Key, such as SECRET_0a1b2c3d4e5f.
+func (m TokenMasker) Mask(match string, _ Rule) string {
+	return hmacToken(match, m.Key)
+}
+
+// HashMasker replaces a secret with Digits hex digits of its SHA-256, as
+// MaskOptions.HashPrefix does.
+type HashMasker struct {
+	Digits int
+}
+
+// Mask returns the first Digits hex digits of the SHA-256 of match, such as
+// sha256:0a1b2c3d.
+func (m HashMasker) Mask(match string, _ Rule) string {
+	return hashToken(match, m.Digits)
+}
+
+// FingerprintMasker replaces a secret with a fixed-width fingerprint of it
+// under Salt, as MaskOptions.FingerprintSalt does.
+type FingerprintMasker struct {
+	Salt []byte
+}
+
+// Mask returns the fingerprint of match, such as ‹secret:ab12cd›.
+func (m FingerprintMasker) Mask(match string, _ Rule) string {
+	return fingerprint(match, m.Salt)
+}
+
+// RuleMasker uses the Replacement of the rule that found a secret, rendered
+// as described for Rule.Replacement, and Masker for rules without one.
+type RuleMasker struct {
+	Masker Masker
+}
+
+// Mask returns the rule's replacement, or what Masker returns for match.
+func (m RuleMasker) Mask(match string, rule Rule) string {
+	if rule.Replacement != "" {
+		return rule.replacementText()
+	}
+	if m.Masker == nil {
+		return FixedMasker{}.Mask(match, rule)
+	}
+	return m.Masker.Mask(match, rule)
+}
+
+// masker returns the Masker configured by the options: Masker when set, or
+// the one built from the other replacement options. TokenKey takes
+// precedence over HashPrefix, and HashPrefix over FingerprintSalt. Rules'
+// own replacements take precedence over all of them unless PreserveLength
+// is set.
+func (o MaskOptions) masker() Masker {
+	if o.Masker != nil {
+		return o.Masker
+	}
+	var m Masker
+	switch {
+	case o.TokenKey != nil:
+		m = TokenMasker{Key: o.TokenKey}
+	case o.HashPrefix > 0:
+		m = HashMasker{Digits: o.HashPrefix}
+	case o.FingerprintSalt != nil:
+		m = FingerprintMasker{Salt: o.FingerprintSalt}
+	default:
+		if o.PreserveLength {
+			m = LengthMasker{}
+		} else {
+			m = FixedMasker{Replacement: o.Replacement}
+		}
+		if o.RevealLast > 0 {
+			m = RevealMasker{Last: o.RevealLast, Masker: m}
+		}
+	}
+	if o.PreserveLength {
+		return m
+	}
+	return RuleMasker{Masker: m}
+}
@@ -0,0 +1,141 @@
+package secret
+
+import (
+	"regexp/syntax"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MatchLenCap is the length MaxMatchLen and MatchLen report for regexes
+// with unbounded repetition, such as the body of a private key block. It is
+// also the overlap that streams carry over between the chunks of an
+// over-long line and between the blocks of binary input.
+const MatchLenCap = maxSecretLen
+
+// MaxMatchLen returns the longest match, in bytes, that any of the rules
+// can make, so that code masking input in pieces can keep enough overlap
+// for no secret to be split. Rules whose regex is unbounded count as
+// MatchLenCap; MatchLen tells which they are.
+func MaxMatchLen(rules []Rule) int {
+	longest := 0
+	for _, rule := range rules {
+		n, _ := MatchLen(rule)
+		longest = max(longest, n)
+	}
+	return longest
+}
+
+// MatchLen returns the longest match, in bytes, of the rule's regex and
+// whether it is bounded at all. For an unbounded regex, or one longer than
+// MatchLenCap, it returns MatchLenCap and false.
+func MatchLen(rule Rule) (int, bool) {
+	if rule.Regex == nil {
+		return 0, true
+	}
+	re, err := syntax.Parse(rule.Regex.String(), syntax.Perl)
+	if err != nil {
+		return MatchLenCap, false
+	}
+	n := regexpMaxLen(re.Simplify())
+	if n < 0 || n > MatchLenCap {
+		return MatchLenCap, false
+	}
+	return n, true
+}
+
+// regexpMaxLen returns the longest string in bytes that re can match, or -1
+// if there is no bound or it exceeds MatchLenCap. A case-folded rune counts
+// as its longest fold, e.g. (?i)k as the three bytes of the Kelvin sign.
+func regexpMaxLen(re *syntax.Regexp) int {
+	switch re.Op {
+	case syntax.OpLiteral:
+		n := 0
+		for _, r := range re.Rune {
+			if re.Flags&syntax.FoldCase != 0 {
+				n += foldLen(r)
+			} else {
+				n += runeLen(r)
+			}
+		}
+		return n
+	case syntax.OpCharClass:
+		n := 0
+		for i := 1; i < len(re.Rune); i += 2 {
+			n = max(n, runeLen(re.Rune[i]))
+		}
+		return n
+	case syntax.OpAnyCharNotNL, syntax.OpAnyChar:
+		return utf8.UTFMax
+	case syntax.OpCapture:
+		return regexpMaxLen(re.Sub[0])
+	case syntax.OpQuest:
+		return regexpMaxLen(re.Sub[0])
+	case syntax.OpStar, syntax.OpPlus:
+		if regexpMaxLen(re.Sub[0]) == 0 {
+			return 0
+		}
+		return -1
+	case syntax.OpRepeat:
+		sub := regexpMaxLen(re.Sub[0])
+		switch {
+		case sub == 0:
+			return 0
+		case sub < 0 || re.Max < 0 || re.Max > MatchLenCap:
+			return -1
+		}
+		return capLen(sub * re.Max)
+	case syntax.OpConcat:
+		total := 0
+		for _, sub := range re.Sub {
+			n := regexpMaxLen(sub)
+			if n < 0 {
+				return -1
+			}
+			total = capLen(total + n)
+			if total < 0 {
+				return -1
+			}
+		}
+		return total
+	case syntax.OpAlternate:
+		longest := 0
+		for _, sub := range re.Sub {
+			n := regexpMaxLen(sub)
+			if n < 0 {
+				return -1
+			}
+			longest = max(longest, n)
+		}
+		return longest
+	default:
+		// Empty-width assertions and OpNoMatch match no bytes.
+		return 0
+	}
+}
+
+// runeLen returns the UTF-8 length of r, counting invalid runes as the
+// replacement character.
+func runeLen(r rune) int {
+	if n := utf8.RuneLen(r); n > 0 {
+		return n
+	}
+	return utf8.RuneLen(utf8.RuneError)
+}
+
+// foldLen returns the longest UTF-8 length among the runes that r matches
+// case-insensitively.
+func foldLen(r rune) int {
+	n := runeLen(r)
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		n = max(n, runeLen(f))
+	}
+	return n
+}
+
+// capLen returns n, or -1 when it exceeds MatchLenCap.
+func capLen(n int) int {
+	if n > MatchLenCap {
+		return -1
+	}
+	return n
+}
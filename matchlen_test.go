@@ -0,0 +1,44 @@
+package secret_test
+
+import (
+	"regexp"
+	"secret"
+	"testing"
+)
+
+func TestMaxMatchLen(t *testing.T) {
+	rule := func(id, re string) secret.Rule {
+		return secret.Rule{ID: id, Regex: regexp.MustCompile(re)}
+	}
+	bounded := []secret.Rule{
+		rule("github", `ghp_[0-9a-zA-Z]{36}`),
+		rule("short", `key=(?:[a-f0-9]{8}|[A-Z]{12})\b`),
+		rule("accented", `pw:[é]{3}`),
+		rule("folded", `(?i)token`),
+	}
+	tests := []struct {
+		rule    secret.Rule
+		want    int
+		bounded bool
+	}{
+		{bounded[0], 40, true},
+		{bounded[1], 16, true},
+		{bounded[2], 9, true},
+		{bounded[3], 7, true}, // (?i)k also matches the 3-byte Kelvin sign.
+		{rule("unbounded", `password=\S+`), secret.MatchLenCap, false},
+		{rule("too long", `[a-z]{900}[0-9]{900}`), secret.MatchLenCap, false},
+	}
+	for _, tt := range tests {
+		n, ok := secret.MatchLen(tt.rule)
+		if n != tt.want || ok != tt.bounded {
+			t.Errorf("MatchLen(%s) = %d, %v, want %d, %v", tt.rule.ID, n, ok, tt.want, tt.bounded)
+		}
+	}
+
+	if got := secret.MaxMatchLen(bounded); got != 40 {
+		t.Errorf("MaxMatchLen(bounded rules) = %d, want 40", got)
+	}
+	if got := secret.MaxMatchLen(secret.BuiltinRules); got != secret.MatchLenCap {
+		t.Errorf("MaxMatchLen(BuiltinRules) = %d, want the cap %d for the private-key rule", got, secret.MatchLenCap)
+	}
+}
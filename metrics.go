@@ -0,0 +1,90 @@
+package secret
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Metrics receives operational measurements from the masker, for example to
+// export them with prometheus/client_golang. Implementations must be safe
+// for concurrent use.
+type Metrics interface {
+	// SecretMasked is called for every masked secret with the ID and
+	// severity of the rule or detector that found it.
+	SecretMasked(ruleID string, severity Severity)
+	// InputMasked is called for every masked input, such as a line or a
+	// chunk of a stream, with its length in bytes and the time spent.
+	InputMasked(bytes int, elapsed time.Duration)
+}
+
+// maskedSecret is a SecretMasked call held back by metricsBuffer.
+type maskedSecret struct {
+	ruleID   string
+	severity Severity
+}
+
+// metricsBuffer holds the SecretMasked calls of a scan that may be
+// abandoned, so they can be reported only once its result is used.
+type metricsBuffer struct {
+	secrets []maskedSecret
+}
+
+func (b *metricsBuffer) SecretMasked(ruleID string, severity Severity) {
+	b.secrets = append(b.secrets, maskedSecret{ruleID, severity})
+}
+
+func (b *metricsBuffer) InputMasked(int, time.Duration) {}
+
+// flush reports the buffered calls to m.
+func (b *metricsBuffer) flush(m Metrics) {
+	for _, s := range b.secrets {
+		m.SecretMasked(s.ruleID, s.severity)
+	}
+}
+
+// Timing is a Metrics that totals the time spent masking, so that callers
+// can report it where they choose instead of the masker printing it. It is
+// safe for concurrent use; the zero value is ready to use.
+type Timing struct {
+	mu      sync.Mutex
+	summary TimingSummary
+}
+
+// TimingSummary is a snapshot of a Timing.
+type TimingSummary struct {
+	// Inputs is the number of masked inputs, such as lines of a stream.
+	Inputs int
+	// Bytes is the total length of the masked inputs.
+	Bytes int64
+	// Elapsed is the total time spent masking them.
+	Elapsed time.Duration
+	// Slowest is the longest time spent on a single input.
+	Slowest time.Duration
+}
+
+// String summarises the timing, e.g.
+// "masked 3 inputs (120 bytes) in 250µs, slowest 100µs".
+func (s TimingSummary) String() string {
+	return fmt.Sprintf("masked %d inputs (%d bytes) in %v, slowest %v", s.Inputs, s.Bytes, s.Elapsed, s.Slowest)
+}
+
+// SecretMasked does nothing; Stats counts secrets.
+func (t *Timing) SecretMasked(string, Severity) {}
+
+// InputMasked adds an input to the totals.
+func (t *Timing) InputMasked(bytes int, elapsed time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.summary.Inputs++
+	t.summary.Bytes += int64(bytes)
+	t.summary.Elapsed += elapsed
+	t.summary.Slowest = max(t.summary.Slowest, elapsed)
+}
+
+// Summary returns the totals so far.
+func (t *Timing) Summary() TimingSummary {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.summary
+}
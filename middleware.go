@@ -0,0 +1,116 @@
+package secret
+
+import (
+	"bytes"
+	"context"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// MaskingMiddleware is an http.Handler that masks secrets in the response
+// bodies of the handler it wraps. The downstream response is buffered in
+// full, masked like a stream and written with a corrected Content-Length,
+// so the wrapped handler cannot stream or flush partial responses.
+//
+// Responses with a Content-Encoding other than identity are passed through
+// unchanged, since compressed bodies cannot be matched.
+type MaskingMiddleware struct {
+	// Options controls how secrets are replaced.
+	Options MaskOptions
+	// MaskContentType reports whether a response with the given
+	// Content-Type is masked. Nil means IsTextContentType. A response
+	// without a Content-Type is sniffed with http.DetectContentType.
+	MaskContentType func(contentType string) bool
+
+	next     http.Handler
+	registry *Registry
+}
+
+// NewMaskingMiddleware returns a MaskingMiddleware that masks secrets
+// matched by rules in the responses of next.
+func NewMaskingMiddleware(next http.Handler, rules []Rule) *MaskingMiddleware {
+	return &MaskingMiddleware{next: next, registry: NewRegistry(rules)}
+}
+
+// ServeHTTP calls the wrapped handler and writes its masked response to w.
+func (m *MaskingMiddleware) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rec := &responseBuffer{header: w.Header(), status: http.StatusOK}
+	m.next.ServeHTTP(rec, r)
+
+	body := rec.body.Bytes()
+	if len(body) > 0 && m.masks(rec.header, body) {
+		var masked bytes.Buffer
+		opts := StreamOptions{Registry: m.registry, Options: m.Options}
+		// Writing to a bytes.Buffer and reading from bytes.Reader cannot fail.
+		_ = maskLines(context.Background(), bytes.NewReader(body), &masked, opts)
+		body = masked.Bytes()
+		rec.header.Set("Content-Length", strconv.Itoa(len(body)))
+	}
+	w.WriteHeader(rec.status)
+	w.Write(body)
+}
+
+// masks reports whether a response with header and body is masked.
+func (m *MaskingMiddleware) masks(header http.Header, body []byte) bool {
+	if enc := header.Get("Content-Encoding"); enc != "" && !strings.EqualFold(enc, "identity") {
+		return false
+	}
+	contentType := header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(body)
+	}
+	if m.MaskContentType != nil {
+		return m.MaskContentType(contentType)
+	}
+	return IsTextContentType(contentType)
+}
+
+// IsTextContentType reports whether contentType describes text: any text/*
+// type, JSON, XML, JavaScript, form data, or a +json or +xml suffix type.
+// Images, archives and other binary types are not text.
+func IsTextContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	switch {
+	case strings.HasPrefix(mediaType, "text/"),
+		strings.HasSuffix(mediaType, "+json"),
+		strings.HasSuffix(mediaType, "+xml"):
+		return true
+	}
+	switch mediaType {
+	case "application/json", "application/xml", "application/javascript",
+		"application/x-www-form-urlencoded", "application/x-ndjson", "application/yaml":
+		return true
+	}
+	return false
+}
+
+// responseBuffer is the http.ResponseWriter handed to the wrapped handler.
+// It shares the real response's header map and buffers the status and body.
+type responseBuffer struct {
+	header      http.Header
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (rb *responseBuffer) Header() http.Header {
+	return rb.header
+}
+
+func (rb *responseBuffer) WriteHeader(status int) {
+	if rb.wroteHeader {
+		return
+	}
+	rb.wroteHeader = true
+	rb.status = status
+}
+
+func (rb *responseBuffer) Write(p []byte) (int, error) {
+	rb.WriteHeader(http.StatusOK)
+	return rb.body.Write(p)
+}
@@ -0,0 +1,246 @@
+package secret
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Pattern is a user-defined secret pattern as loaded from patterns.yaml.
+type Pattern struct {
+	Name       string `yaml:"name"`
+	Regex      string `yaml:"regex"`
+	Confidence string `yaml:"confidence"`
+	// Disabled, in a file read by ReadPatternFiles, removes the pattern of
+	// the same name defined by an earlier file. PatternsToRules skips
+	// disabled patterns.
+	Disabled bool `yaml:"disabled"`
+
+	// Line is the line of the regex in the source file, when known.
+	Line int `yaml:"-"`
+
+	// re is the compiled Regex, set by ReadPatterns.
+	re *regexp.Regexp
+}
+
+// patternEntry is one item of the patterns.yaml list, whose fields are
+// nested under a "pattern" key.
+type patternEntry struct {
+	Pattern yaml.Node `yaml:"pattern"`
+}
+
+// ReadPatterns loads the patterns defined in a patterns.yaml file and
+// compiles their regexes. If any regex is invalid, the returned error lists
+// every offending pattern with its name and line.
+func ReadPatterns(filename string) ([]Pattern, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readPatterns(filename, f)
+}
+
+// ReadPatternsFromReader is like ReadPatterns but reads the patterns.yaml
+// document from r, such as a file in an embed.FS, an HTTP response body or
+// stdin. Errors locate patterns by line only.
+func ReadPatternsFromReader(r io.Reader) ([]Pattern, error) {
+	return readPatterns("patterns", r)
+}
+
+// readPatterns reads and parses the patterns in r, which is named name in
+// errors.
+func readPatterns(name string, r io.Reader) ([]Pattern, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", name, err)
+	}
+	return parsePatterns(name, data)
+}
+
+// parsePatterns parses and compiles the patterns in data, which was read
+// from filename.
+func parsePatterns(filename string, data []byte) ([]Pattern, error) {
+	var entries []patternEntry
+	if err := yaml.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", filename, err)
+	}
+
+	patterns := make([]Pattern, 0, len(entries))
+	var errs []error
+	for _, e := range entries {
+		var p Pattern
+		if err := e.Pattern.Decode(&p); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", filename, err)
+		}
+		p.Line = regexLine(&e.Pattern)
+		if p.Disabled {
+			patterns = append(patterns, p)
+			continue
+		}
+
+		re, err := regexp.Compile(p.Regex)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s:%d: pattern %q: %w", filename, p.Line, p.Name, err))
+			continue
+		}
+		p.re = re
+		patterns = append(patterns, p)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return patterns, nil
+}
+
+// ReadPatternFiles loads layered patterns.yaml files, such as organisation
+// defaults followed by team overrides, and merges their patterns in order.
+// A pattern replaces the one of the same name from an earlier file, keeping
+// its position, and a pattern marked disabled removes it. A name defined
+// twice in the same file, and a disabled name that no earlier file defines,
+// are reported as errors. Unnamed patterns are always added.
+func ReadPatternFiles(filenames ...string) ([]Pattern, error) {
+	var merged []Pattern
+	index := make(map[string]int)
+	for _, filename := range filenames {
+		patterns, err := ReadPatterns(filename)
+		if err != nil {
+			return nil, err
+		}
+		var errs []error
+		lines := make(map[string]int)
+		for _, p := range patterns {
+			if p.Name == "" {
+				if p.Disabled {
+					errs = append(errs, fmt.Errorf("%s:%d: disabled pattern has no name", filename, p.Line))
+				} else {
+					merged = append(merged, p)
+				}
+				continue
+			}
+			if line, ok := lines[p.Name]; ok {
+				errs = append(errs, fmt.Errorf("%s:%d: pattern %q is already defined on line %d", filename, p.Line, p.Name, line))
+				continue
+			}
+			lines[p.Name] = p.Line
+
+			i, ok := index[p.Name]
+			switch {
+			case p.Disabled && !ok:
+				errs = append(errs, fmt.Errorf("%s:%d: disabled pattern %q is not defined by an earlier file", filename, p.Line, p.Name))
+			case p.Disabled:
+				merged[i].Disabled = true
+			case ok:
+				merged[i] = p
+			default:
+				index[p.Name] = len(merged)
+				merged = append(merged, p)
+			}
+		}
+		if err := errors.Join(errs...); err != nil {
+			return nil, err
+		}
+	}
+
+	patterns := merged[:0]
+	for _, p := range merged {
+		if !p.Disabled {
+			patterns = append(patterns, p)
+		}
+	}
+	return patterns, nil
+}
+
+// regexLine returns the line of the regex value within a pattern mapping,
+// falling back to the line of the mapping itself.
+func regexLine(node *yaml.Node) int {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == "regex" {
+			return node.Content[i+1].Line
+		}
+	}
+	return node.Line
+}
+
+// PatternsToRules compiles patterns into rules that can be passed to
+// MaskSecretsOnString. The pattern name becomes the rule title and its
+// confidence both the rule severity and, normalised by patternConfidence,
+// the rule confidence. Disabled patterns are skipped. An error is returned
+// for the first pattern whose regex does not compile.
+func PatternsToRules(patterns []Pattern) ([]Rule, error) {
+	rules := make([]Rule, 0, len(patterns))
+	for i, p := range patterns {
+		if p.Disabled {
+			continue
+		}
+		re := p.re
+		if re == nil {
+			var err error
+			if re, err = regexp.Compile(p.Regex); err != nil {
+				return nil, fmt.Errorf("pattern %q: %w", p.Name, err)
+			}
+		}
+		rules = append(rules, Rule{
+			ID:         patternID(p, i),
+			Severity:   confidenceSeverity(p.Confidence),
+			Confidence: patternConfidence(p.Confidence),
+			Title:      p.Name,
+			Regex:      re,
+		})
+	}
+	return rules, nil
+}
+
+// RulesWithPatterns returns a copy of BuiltinRules with the rules compiled
+// from patterns appended, so one masking pass covers both.
+func RulesWithPatterns(patterns []Pattern) ([]Rule, error) {
+	extra, err := PatternsToRules(patterns)
+	if err != nil {
+		return nil, err
+	}
+	rules := make([]Rule, 0, len(BuiltinRules)+len(extra))
+	rules = append(rules, BuiltinRules...)
+	return append(rules, extra...), nil
+}
+
+// patternID derives a rule ID from the pattern name, e.g. "AWS Access Key"
+// becomes "aws-access-key". Unnamed patterns are identified by position.
+func patternID(p Pattern, index int) string {
+	id := strings.Join(strings.FieldsFunc(strings.ToLower(p.Name), func(r rune) bool {
+		return !('a' <= r && r <= 'z' || '0' <= r && r <= '9')
+	}), "-")
+	if id == "" {
+		return fmt.Sprintf("pattern-%d", index)
+	}
+	return id
+}
+
+// confidenceSeverity maps a pattern confidence onto the rule severity scale.
+// Unknown or missing confidences are treated as MEDIUM.
+func confidenceSeverity(confidence string) string {
+	switch strings.ToUpper(confidence) {
+	case "LOW", "MEDIUM", "HIGH", "CRITICAL":
+		return strings.ToUpper(confidence)
+	default:
+		return "MEDIUM"
+	}
+}
+
+// patternConfidence maps a pattern confidence onto the confidence scale.
+// CRITICAL counts as HIGH; unknown or missing confidences are treated as
+// MEDIUM, as in confidenceSeverity.
+func patternConfidence(confidence string) string {
+	if strings.EqualFold(confidence, "CRITICAL") {
+		return ConfidenceHigh.String()
+	}
+	level, err := ParseConfidence(confidence)
+	if err != nil {
+		return ConfidenceMedium.String()
+	}
+	return level.String()
+}
@@ -0,0 +1,71 @@
+package secret
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// CategoryPII is the Category of rules that detect personal data rather
+// than credentials.
+const CategoryPII = "pii"
+
+const (
+	ipv4Octet = `(25[0-5]|2[0-4][0-9]|1[0-9]{2}|[1-9]?[0-9])`
+	hexGroup  = `[0-9A-Fa-f]{1,4}`
+)
+
+// ipv6Address matches full and compressed IPv6 addresses with at least two
+// groups, so that "::", "::1" and identifiers like "Class::method" are not
+// taken for addresses. Alternatives that continue past "::" come first
+// because the leftmost matching alternative wins.
+var ipv6Address = fmt.Sprintf(
+	`(%[1]s:){7}%[1]s|(%[1]s:){1,6}:%[1]s|(%[1]s:){1,5}(:%[1]s){1,2}|(%[1]s:){1,4}(:%[1]s){1,3}|(%[1]s:){1,3}(:%[1]s){1,4}|(%[1]s:){1,2}(:%[1]s){1,5}|%[1]s:(:%[1]s){1,6}|(%[1]s:){2,7}:|:(:%[1]s){2,7}`,
+	hexGroup)
+
+// PIIRules detect personal data. They are not part of BuiltinRules; append
+// them to the rules passed to the masking functions to redact PII as well.
+var PIIRules = []Rule{
+	{
+		ID:       "email-address",
+		Title:    "Email address",
+		Severity: "MEDIUM",
+		Regex:    regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`),
+		Keywords: []string{"@"},
+		Category: CategoryPII,
+		Tags:     []string{"pii"},
+	},
+	{
+		ID:                "ipv4-address",
+		Title:             "IPv4 address",
+		Severity:          "LOW",
+		Regex:             regexp.MustCompile(fmt.Sprintf(`(^|[^0-9.])(?P<secret>(%[1]s\.){3}%[1]s)($|[^0-9.])`, ipv4Octet)),
+		SecretGroupName:   "secret",
+		Keywords:          []string{"."},
+		Category:          CategoryPII,
+		Tags:              []string{"pii"},
+		resumeAfterSecret: true,
+	},
+	{
+		ID:                "ipv6-address",
+		Title:             "IPv6 address",
+		Severity:          "LOW",
+		Regex:             regexp.MustCompile(fmt.Sprintf(`(^|[^0-9A-Za-z:])(?P<secret>%s)($|[^0-9A-Za-z:])`, ipv6Address)),
+		SecretGroupName:   "secret",
+		Keywords:          []string{":"},
+		Category:          CategoryPII,
+		Tags:              []string{"pii"},
+		resumeAfterSecret: true,
+	},
+	{
+		// Area 000, 666 and 900-999, group 00 and serial 0000 are never issued.
+		ID:                "us-ssn",
+		Title:             "US Social Security number",
+		Severity:          "HIGH",
+		Regex:             regexp.MustCompile(`(^|[^0-9-])(?P<secret>(00[1-9]|0[1-9][0-9]|[1-5][0-9]{2}|6[0-5][0-9]|66[0-57-9]|6[7-9][0-9]|[78][0-9]{2})-(0[1-9]|[1-9][0-9])-(000[1-9]|00[1-9][0-9]|0[1-9][0-9]{2}|[1-9][0-9]{3}))($|[^0-9-])`),
+		SecretGroupName:   "secret",
+		Keywords:          []string{"-"},
+		Category:          CategoryPII,
+		Tags:              []string{"pii"},
+		resumeAfterSecret: true,
+	},
+}
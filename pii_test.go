@@ -0,0 +1,48 @@
+package secret_test
+
+import (
+	"secret"
+	"testing"
+)
+
+func TestPIIRules(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"contact test@presidio.site today", "contact ****** today"},
+		{"client 192.168.0.1 connected", "client ****** connected"},
+		{"client 2001:db8::1 connected", "client ****** connected"},
+		{"listen fe80::1ff:fe23:4567:890a%eth0", "listen ******%eth0"},
+		{"ssn 078-05-1126.", "ssn ******."},
+		// A separator is the trailing boundary of one value and the
+		// leading boundary of the next.
+		{"hosts 10.0.0.1 10.0.0.2 10.0.0.3", "hosts ****** ****** ******"},
+		{"hosts=10.0.0.1,10.0.0.2", "hosts=******,******"},
+		{"a 2001:db8::1 2001:db8::2", "a ****** ******"},
+		{"ssn 078-05-1126 078-05-1127", "ssn ****** ******"},
+		// Never-issued SSNs, versions, times and other look-alikes survive.
+		{"ssn 666-05-1126 and 078-00-1126", "ssn 666-05-1126 and 078-00-1126"},
+		{"version 1.2.3.4.5 at 10:15:33", "version 1.2.3.4.5 at 10:15:33"},
+		{"mac 00:1A:2B:3C:4D:5E and Foo::bar", "mac 00:1A:2B:3C:4D:5E and Foo::bar"},
+		{"octet 256.1.1.1", "octet 256.1.1.1"},
+	}
+
+	for _, tt := range tests {
+		if got := secret.MaskSecretsOnString(tt.input, secret.PIIRules); got != tt.want {
+			t.Errorf("MaskSecretsOnString(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestPIIRulesNotBuiltin(t *testing.T) {
+	for _, r := range secret.PIIRules {
+		if r.Category != secret.CategoryPII {
+			t.Errorf("rule %s has category %q, want %q", r.ID, r.Category, secret.CategoryPII)
+		}
+	}
+	input := "contact test@presidio.site from 192.168.0.1"
+	if got := secret.MaskSecretsOnString(input, secret.BuiltinRules); got != input {
+		t.Errorf("BuiltinRules masked PII: %q", got)
+	}
+}
@@ -0,0 +1,95 @@
+package secret
+
+import (
+	"sync"
+)
+
+// Registry is a set of rules that is safe for concurrent use. BuiltinRules
+// is a plain slice with no synchronisation, so callers that change rules
+// while masking is in progress must do so through a Registry.
+//
+// Every change installs a new slice rather than modifying the current one,
+// so a masking pass that has already read the rules is never affected by a
+// concurrent change.
+type Registry struct {
+	mu      sync.RWMutex
+	ruleSet []Rule
+	// index is the keyword index of ruleSet, rebuilt with every change.
+	index *keywordIndex
+}
+
+// DefaultRegistry is seeded with BuiltinRules and used by the streaming
+// masker when no registry is given.
+var DefaultRegistry = NewRegistry(BuiltinRules)
+
+// NewRegistry returns a registry holding a copy of rules.
+func NewRegistry(rules []Rule) *Registry {
+	r := &Registry{}
+	r.set(append([]Rule(nil), rules...))
+	return r
+}
+
+// set installs rules and their keyword index. Callers other than
+// NewRegistry must hold r.mu.
+func (r *Registry) set(rules []Rule) {
+	r.ruleSet = rules
+	r.index = newKeywordIndex(rules)
+}
+
+// AddRule appends rule to the registry.
+func (r *Registry) AddRule(rule Rule) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rules := make([]Rule, 0, len(r.ruleSet)+1)
+	rules = append(rules, r.ruleSet...)
+	r.set(append(rules, rule))
+}
+
+// RemoveRule removes every rule with the given ID and reports whether any
+// was found.
+func (r *Registry) RemoveRule(id string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rules := filterRules(r.ruleSet, func(rule Rule) bool {
+		return rule.ID != id
+	})
+	removed := len(rules) != len(r.ruleSet)
+	r.set(rules)
+	return removed
+}
+
+// ReplaceRules replaces all rules in the registry with a copy of rules.
+func (r *Registry) ReplaceRules(rules []Rule) {
+	rules = append([]Rule(nil), rules...)
+	index := newKeywordIndex(rules)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ruleSet, r.index = rules, index
+}
+
+// Snapshot returns a copy of the current rules.
+func (r *Registry) Snapshot() []Rule {
+	return append([]Rule(nil), r.rules()...)
+}
+
+// Mask masks input with the current rules according to opts.
+func (r *Registry) Mask(input string, opts MaskOptions) string {
+	rules, index := r.indexed()
+	opts.keywords = index
+	return MaskSecretsOnStringWithOptions(input, rules, opts)
+}
+
+// rules returns the current rule slice without copying it. Callers must not
+// modify the returned slice.
+func (r *Registry) rules() []Rule {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.ruleSet
+}
+
+// indexed is like rules but also returns the keyword index of the rules.
+func (r *Registry) indexed() ([]Rule, *keywordIndex) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.ruleSet, r.index
+}
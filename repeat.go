@@ -0,0 +1,75 @@
+package secret
+
+import (
+	"crypto/sha256"
+	"strconv"
+	"sync"
+)
+
+// RepeatCounter remembers the secrets masked during a session, so that
+// repeated occurrences of the same secret can be told apart from distinct
+// ones. Only hashes of the secrets are kept. The zero value is ready to use
+// and it is safe for concurrent use.
+type RepeatCounter struct {
+	mu   sync.Mutex
+	seen map[[sha256.Size]byte]int
+	// parent, for a counter made by fork, holds the occurrences seen
+	// before it; seen then holds only the ones it added.
+	parent *RepeatCounter
+}
+
+// next records an occurrence of secret and returns how many times it was
+// seen before.
+func (c *RepeatCounter) next(secret string) int {
+	key := sha256.Sum256([]byte(secret))
+	n := 0
+	if c.parent != nil {
+		n = c.parent.count(key)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.seen == nil {
+		c.seen = make(map[[sha256.Size]byte]int)
+	}
+	n += c.seen[key]
+	c.seen[key]++
+	return n
+}
+
+// count returns how many times the secret hashing to key was seen.
+func (c *RepeatCounter) count(key [sha256.Size]byte) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.seen[key]
+}
+
+// fork returns a counter that goes on from the occurrences c has seen but
+// keeps the ones it adds to itself, for a scan whose result may be
+// discarded. commit adds them to c.
+func (c *RepeatCounter) fork() *RepeatCounter {
+	return &RepeatCounter{parent: c}
+}
+
+// commit adds the occurrences recorded by a counter made by fork to the
+// counter it was forked from.
+func (c *RepeatCounter) commit() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.parent.mu.Lock()
+	defer c.parent.mu.Unlock()
+	if c.parent.seen == nil {
+		c.parent.seen = make(map[[sha256.Size]byte]int)
+	}
+	for key, n := range c.seen {
+		c.parent.seen[key] += n
+	}
+}
+
+// repeatSuffix returns the marker appended to the replacement of a secret
+// that was seen n times before, e.g. "(2)", or "" for a first occurrence.
+func repeatSuffix(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return "(" + strconv.Itoa(n) + ")"
+}
@@ -0,0 +1,60 @@
+package secret
+
+import (
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// replacementTemplates caches the parsed Rule.Replacement templates by text.
+var replacementTemplates sync.Map // string -> *template.Template
+
+// replacementData is what a Rule.Replacement template is executed with.
+type replacementData struct {
+	ID, Title, Severity string
+}
+
+// parseReplacement parses a Rule.Replacement template.
+func parseReplacement(text string) (*template.Template, error) {
+	if t, ok := replacementTemplates.Load(text); ok {
+		return t.(*template.Template), nil
+	}
+	t, err := template.New("replacement").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	replacementTemplates.Store(text, t)
+	return t, nil
+}
+
+// replacementText renders the rule's Replacement. Text without template
+// actions is returned as is, and so is a template that fails to parse or
+// execute; ValidateRules reports those.
+func (r *Rule) replacementText() string {
+	if !strings.Contains(r.Replacement, "{{") {
+		return r.Replacement
+	}
+	t, err := parseReplacement(r.Replacement)
+	if err != nil {
+		return r.Replacement
+	}
+	var sb strings.Builder
+	if err := t.Execute(&sb, replacementData{r.ID, r.Title, r.Severity}); err != nil {
+		return r.Replacement
+	}
+	return sb.String()
+}
+
+// ruleReplacement returns the text that should stand in for a secret
+// matched by rule, which is nil for secrets found without one, using the
+// options' Masker. The Repeats marker is appended to any replacement.
+func (o MaskOptions) ruleReplacement(rule *Rule, secret string) string {
+	if rule == nil {
+		rule = &Rule{}
+	}
+	text := o.masker().Mask(secret, *rule)
+	if o.Repeats != nil {
+		text += repeatSuffix(o.Repeats.next(secret))
+	}
+	return text
+}
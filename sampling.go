@@ -0,0 +1,55 @@
+package secret
+
+import "strings"
+
+// Sampling trades completeness for speed when masking very high-volume
+// streams. Every line is still checked by the rules whose keywords occur in
+// it, which covers all builtin rules. Only the lines it selects are also
+// scanned by the rules without keywords, such as most rules compiled from
+// patterns, by the entropy detector and by the detectors: lines at least
+// MinLength bytes long, and lines in which the keywords of at least
+// MinKeywordHits rules occur. A zero threshold selects no lines.
+//
+// This is not safe for secrets that only a keyword-less rule or a detector
+// can find: on a line that is not selected they are left in the clear. Use
+// Sampling only where the cost of scanning every line fully is the bigger
+// risk, and prefer adding keywords to custom rules over relying on it.
+type Sampling struct {
+	// MinLength is the length in bytes from which lines are scanned fully.
+	MinLength int
+	// MinKeywordHits is the number of rules whose keywords must occur in a
+	// line for it to be scanned fully.
+	MinKeywordHits int
+}
+
+// fullScan reports whether input is selected for the full scan.
+func (s *Sampling) fullScan(input string, rules []Rule, opts MaskOptions) bool {
+	if s.MinLength > 0 && len(input) >= s.MinLength {
+		return true
+	}
+	if s.MinKeywordHits <= 0 {
+		return false
+	}
+	return keywordHits(input, rules, opts) >= s.MinKeywordHits
+}
+
+// keywordHits returns the number of rules with keywords that pass the
+// keyword pre-filter on input.
+func keywordHits(input string, rules []Rule, opts MaskOptions) int {
+	hits := 0
+	if opts.keywords != nil && opts.keywords.indexes(rules) {
+		for i, run := range opts.keywords.candidates(input) {
+			if run && len(rules[i].Keywords) > 0 {
+				hits++
+			}
+		}
+		return hits
+	}
+	lowerInput := strings.ToLower(input)
+	for _, rule := range rules {
+		if len(rule.Keywords) > 0 && hasKeyword(input, lowerInput, rule) {
+			hits++
+		}
+	}
+	return hits
+}
@@ -0,0 +1,62 @@
+package secret
+
+// RulesExcluding returns a copy of BuiltinRules without the rules with the
+// given IDs. IDs that match no rule are ignored.
+func RulesExcluding(ids ...string) []Rule {
+	excluded := idSet(ids)
+	return filterRules(BuiltinRules, func(r Rule) bool {
+		return !excluded[r.ID]
+	})
+}
+
+// RulesIncluding returns a copy of the BuiltinRules with the given IDs, in
+// their BuiltinRules order. IDs that match no rule are ignored.
+func RulesIncluding(ids ...string) []Rule {
+	included := idSet(ids)
+	return filterRules(BuiltinRules, func(r Rule) bool {
+		return included[r.ID]
+	})
+}
+
+// RulesBySeverity returns a copy of the BuiltinRules whose severity is at
+// least min.
+func RulesBySeverity(min Severity) []Rule {
+	return filterRules(BuiltinRules, func(r Rule) bool {
+		return r.SeverityLevel() >= min
+	})
+}
+
+// RulesByTag returns a copy of the BuiltinRules and PIIRules that carry at
+// least one of the given tags, in that order. Tags that match no rule are
+// ignored.
+func RulesByTag(tags ...string) []Rule {
+	wanted := idSet(tags)
+	keep := func(r Rule) bool {
+		for _, tag := range r.Tags {
+			if wanted[tag] {
+				return true
+			}
+		}
+		return false
+	}
+	return append(filterRules(BuiltinRules, keep), filterRules(PIIRules, keep)...)
+}
+
+// filterRules returns the rules for which keep reports true.
+func filterRules(rules []Rule, keep func(Rule) bool) []Rule {
+	var filtered []Rule
+	for _, r := range rules {
+		if keep(r) {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered
+}
+
+func idSet(ids []string) map[string]bool {
+	set := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
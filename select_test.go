@@ -0,0 +1,107 @@
+package secret_test
+
+import (
+	"secret"
+	"testing"
+)
+
+func ruleIDs(rules []secret.Rule) map[string]bool {
+	ids := make(map[string]bool, len(rules))
+	for _, r := range rules {
+		ids[r.ID] = true
+	}
+	return ids
+}
+
+func TestRulesExcluding(t *testing.T) {
+	rules := secret.RulesExcluding("twilio-api-key", "no-such-rule")
+	ids := ruleIDs(rules)
+	if ids["twilio-api-key"] {
+		t.Error("twilio-api-key was not excluded")
+	}
+	if !ids["github-pat"] {
+		t.Error("github-pat is missing")
+	}
+	if len(rules) != len(secret.BuiltinRules)-1 {
+		t.Errorf("got %d rules, want %d", len(rules), len(secret.BuiltinRules)-1)
+	}
+}
+
+func TestRulesIncluding(t *testing.T) {
+	rules := secret.RulesIncluding("github-pat", "aws-access-key-id", "no-such-rule")
+	ids := ruleIDs(rules)
+	if len(rules) != 2 || !ids["github-pat"] || !ids["aws-access-key-id"] {
+		t.Errorf("RulesIncluding returned %v", ids)
+	}
+}
+
+func TestRulesBySeverity(t *testing.T) {
+	rules := secret.RulesBySeverity(secret.SeverityHigh)
+	ids := ruleIDs(rules)
+	if !ids["aws-access-key-id"] || !ids["private-key"] {
+		t.Errorf("HIGH and CRITICAL rules missing from %v", ids)
+	}
+	for _, r := range rules {
+		if r.SeverityLevel() < secret.SeverityHigh {
+			t.Errorf("rule %s with severity %q is below HIGH", r.ID, r.Severity)
+		}
+	}
+	if ids["stripe-publishable-token"] {
+		t.Error("LOW rule stripe-publishable-token was included")
+	}
+}
+
+func TestRulesByTag(t *testing.T) {
+	vcs := ruleIDs(secret.RulesByTag("vcs"))
+	want := []string{"github-pat", "github-oauth", "github-app-token", "github-refresh-token", "github-fine-grained-pat", "gitlab-pat", "bitbucket-client-id", "bitbucket-client-secret"}
+	if len(vcs) != len(want) {
+		t.Errorf("vcs rules = %v, want %v", vcs, want)
+	}
+	for _, id := range want {
+		if !vcs[id] {
+			t.Errorf("vcs rules lack %s", id)
+		}
+	}
+
+	// Selection is an OR across tags, and a rule with several tags is
+	// returned once.
+	rules := secret.RulesByTag("payment", "pii", "database", "generic", "no-such-tag")
+	ids := ruleIDs(rules)
+	for _, id := range []string{"stripe-secret-token", "email-address", "us-ssn", "connection-string-password", "jwt-token"} {
+		if !ids[id] {
+			t.Errorf("%s missing from %v", id, ids)
+		}
+	}
+	if ids["aws-access-key-id"] {
+		t.Error("cloud rule aws-access-key-id was included")
+	}
+	if len(rules) != len(ids) {
+		t.Errorf("got %d rules for %d IDs", len(rules), len(ids))
+	}
+
+	if rules := secret.RulesByTag(); len(rules) != 0 {
+		t.Errorf("RulesByTag() returned %d rules, want none", len(rules))
+	}
+}
+
+func TestBuiltinRulesHaveTags(t *testing.T) {
+	for _, r := range append(secret.BuiltinRules[:len(secret.BuiltinRules):len(secret.BuiltinRules)], secret.PIIRules...) {
+		if len(r.Tags) == 0 {
+			t.Errorf("rule %s has no tags", r.ID)
+		}
+	}
+}
+
+func TestParseSeverity(t *testing.T) {
+	for _, s := range []string{"LOW", "medium", " High ", "CRITICAL"} {
+		if _, err := secret.ParseSeverity(s); err != nil {
+			t.Errorf("ParseSeverity(%q): %v", s, err)
+		}
+	}
+	if _, err := secret.ParseSeverity("urgent"); err == nil {
+		t.Error("ParseSeverity(urgent) succeeded")
+	}
+	if !(secret.SeverityLow < secret.SeverityMedium && secret.SeverityMedium < secret.SeverityHigh && secret.SeverityHigh < secret.SeverityCritical) {
+		t.Error("severities are not ordered")
+	}
+}
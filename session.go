@@ -0,0 +1,55 @@
+package secret
+
+import "sync"
+
+// MaskSession masks inputs from many goroutines with one rule set and one
+// set of options, as a server masking each request does. It compiles the
+// keyword index of the rules once and keeps the state of every call, such
+// as the secrets counted so far, local to that call, so calls share only
+// values they do not modify: the rules, their regexes and the options.
+// The counts are merged into the session under a lock once a call is done.
+//
+// State reachable from the options is shared by every call: Repeats,
+// Metrics such as Timing and SlowRules are safe for concurrent use, and
+// custom Detectors and Maskers must be too. A Stats passed to several
+// concurrent streams is not; use a session per stream or Stats instead.
+type MaskSession struct {
+	rules []Rule
+	opts  MaskOptions
+
+	mu    sync.Mutex
+	stats Stats
+}
+
+// NewMaskSession returns a session masking with a copy of rules according
+// to opts.
+func NewMaskSession(rules []Rule, opts MaskOptions) *MaskSession {
+	rules = append([]Rule(nil), rules...)
+	opts.keywords = newKeywordIndex(rules)
+	return &MaskSession{rules: rules, opts: opts}
+}
+
+// Mask masks input as MaskSecretsOnStringWithOptions does. It is safe for
+// concurrent use.
+func (s *MaskSession) Mask(input string) string {
+	var stats Stats
+	opts := s.opts
+	opts.stats = &stats
+	masked := MaskSecretsOnStringWithOptions(input, s.rules, opts)
+	if stats.Total() > 0 {
+		s.mu.Lock()
+		s.stats.merge(&stats)
+		s.mu.Unlock()
+	}
+	return masked
+}
+
+// Stats returns a copy of the counts of the secrets masked by the session
+// so far.
+func (s *MaskSession) Stats() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var stats Stats
+	stats.merge(&s.stats)
+	return stats
+}
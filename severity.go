@@ -0,0 +1,113 @@
+package secret
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity is the ordered severity of a rule: LOW < MEDIUM < HIGH < CRITICAL.
+type Severity int
+
+const (
+	// SeverityUnknown is the level of a rule whose severity is missing or
+	// unrecognised. It ranks below every named severity.
+	SeverityUnknown Severity = iota
+	SeverityLow
+	SeverityMedium
+	SeverityHigh
+	SeverityCritical
+)
+
+var severityNames = map[Severity]string{
+	SeverityUnknown:  "UNKNOWN",
+	SeverityLow:      "LOW",
+	SeverityMedium:   "MEDIUM",
+	SeverityHigh:     "HIGH",
+	SeverityCritical: "CRITICAL",
+}
+
+// String returns the severity name as used in Rule.Severity.
+func (s Severity) String() string {
+	if name, ok := severityNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+// ParseSeverity parses a severity name such as "HIGH", ignoring case.
+func ParseSeverity(s string) (Severity, error) {
+	upper := strings.ToUpper(strings.TrimSpace(s))
+	for level, name := range severityNames {
+		if level != SeverityUnknown && name == upper {
+			return level, nil
+		}
+	}
+	return SeverityUnknown, fmt.Errorf("unknown severity %q", s)
+}
+
+// Confidence is the ordered likelihood that a rule's matches are real
+// secrets: LOW < MEDIUM < HIGH.
+type Confidence int
+
+const (
+	// ConfidenceUnknown is the level of a rule whose confidence is missing
+	// or unrecognised, such as every builtin rule. It ranks below every
+	// named confidence.
+	ConfidenceUnknown Confidence = iota
+	ConfidenceLow
+	ConfidenceMedium
+	ConfidenceHigh
+)
+
+var confidenceNames = map[Confidence]string{
+	ConfidenceUnknown: "UNKNOWN",
+	ConfidenceLow:     "LOW",
+	ConfidenceMedium:  "MEDIUM",
+	ConfidenceHigh:    "HIGH",
+}
+
+// String returns the confidence name as used in Rule.Confidence.
+func (c Confidence) String() string {
+	if name, ok := confidenceNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("Confidence(%d)", int(c))
+}
+
+// ParseConfidence parses a confidence name such as "HIGH", ignoring case.
+func ParseConfidence(s string) (Confidence, error) {
+	upper := strings.ToUpper(strings.TrimSpace(s))
+	for level, name := range confidenceNames {
+		if level != ConfidenceUnknown && name == upper {
+			return level, nil
+		}
+	}
+	return ConfidenceUnknown, fmt.Errorf("unknown confidence %q", s)
+}
+
+// ConfidenceLevel returns the parsed confidence of the rule, or
+// ConfidenceUnknown when its Confidence string is empty or not recognised.
+func (r Rule) ConfidenceLevel() Confidence {
+	level, _ := ParseConfidence(r.Confidence)
+	return level
+}
+
+// outranks reports whether rule a takes precedence over rule b for matches
+// starting at the same offset: the higher severity wins, then the higher
+// confidence. Rules of equal rank keep their order.
+func outranks(a, b *Rule) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	if sa, sb := a.SeverityLevel(), b.SeverityLevel(); sa != sb {
+		return sa > sb
+	}
+	return a.ConfidenceLevel() > b.ConfidenceLevel()
+}
+
+// SeverityLevel returns the parsed severity of the rule, or SeverityUnknown
+// when its Severity string is not recognised.
+func (r Rule) SeverityLevel() Severity {
+	level, _ := ParseSeverity(r.Severity)
+	return level
+}
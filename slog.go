@@ -0,0 +1,64 @@
+package secret
+
+import (
+	"context"
+	"log/slog"
+)
+
+// maskingHandler is a slog.Handler that masks secrets in records before
+// passing them to another handler.
+type maskingHandler struct {
+	inner slog.Handler
+	rules []Rule
+}
+
+// NewMaskingHandler returns a slog.Handler that masks secrets matched by
+// rules in the message and in string attribute values, including those
+// nested in groups, and then delegates to inner. Attribute keys are never
+// masked.
+func NewMaskingHandler(inner slog.Handler, rules []Rule) slog.Handler {
+	return &maskingHandler{inner: inner, rules: rules}
+}
+
+func (h *maskingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.inner.Enabled(ctx, level)
+}
+
+func (h *maskingHandler) Handle(ctx context.Context, r slog.Record) error {
+	masked := slog.NewRecord(r.Time, r.Level, MaskSecretsOnString(r.Message, h.rules), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		masked.AddAttrs(h.maskAttr(a))
+		return true
+	})
+	return h.inner.Handle(ctx, masked)
+}
+
+func (h *maskingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	masked := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		masked[i] = h.maskAttr(a)
+	}
+	return &maskingHandler{inner: h.inner.WithAttrs(masked), rules: h.rules}
+}
+
+func (h *maskingHandler) WithGroup(name string) slog.Handler {
+	return &maskingHandler{inner: h.inner.WithGroup(name), rules: h.rules}
+}
+
+// maskAttr masks the value of a, resolving slog.LogValuer values first so
+// that their string form is masked too.
+func (h *maskingHandler) maskAttr(a slog.Attr) slog.Attr {
+	a.Value = a.Value.Resolve()
+	switch a.Value.Kind() {
+	case slog.KindString:
+		a.Value = slog.StringValue(MaskSecretsOnString(a.Value.String(), h.rules))
+	case slog.KindGroup:
+		group := a.Value.Group()
+		masked := make([]slog.Attr, len(group))
+		for i, ga := range group {
+			masked[i] = h.maskAttr(ga)
+		}
+		a.Value = slog.GroupValue(masked...)
+	}
+	return a
+}
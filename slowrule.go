@@ -0,0 +1,119 @@
+package secret
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultSlowRuleStrikes is the number of consecutive over-budget inputs
+// after which a SlowRuleGuard with zero Strikes disables a rule.
+const DefaultSlowRuleStrikes = 3
+
+// SlowRuleGuard times each rule on each input and disables a rule that is
+// consistently slower than its budget, so that one pathological pattern,
+// such as a user pattern with large repetition counts applied to long
+// lines, degrades only its own coverage instead of the whole pipeline.
+// Share one guard across the calls of a masking session; it is safe for
+// concurrent use.
+type SlowRuleGuard struct {
+	// Budget is the longest a rule may take to match a single input.
+	// Zero disables no rule but still records timings.
+	Budget time.Duration
+	// Strikes is the number of consecutive inputs on which a rule must
+	// exceed Budget to be disabled. Zero means DefaultSlowRuleStrikes.
+	Strikes int
+	// OnDisable, when set, is called once for each rule when it is
+	// disabled, so the caller can log a warning.
+	OnDisable func(ruleID string, timing RuleTiming)
+	// Rules, when set, lists the IDs of the rules that may be disabled,
+	// such as those compiled from user patterns. Other rules are timed but
+	// always kept, so that a loaded machine cannot turn off builtin rules.
+	Rules []string
+
+	mu      sync.Mutex
+	timings map[string]*RuleTiming
+	// guarded is the set of Rules, built on first use.
+	guarded map[string]bool
+}
+
+// RuleTiming is the time a rule has spent matching inputs.
+type RuleTiming struct {
+	// Calls is the number of inputs the rule was matched against; inputs
+	// skipped by the keyword pre-filter are not counted.
+	Calls int
+	Total time.Duration
+	Max   time.Duration
+	// Disabled is set once the rule has been disabled.
+	Disabled bool
+
+	// strikes counts the consecutive over-budget inputs.
+	strikes int
+}
+
+// Timings returns the timings recorded so far by rule ID.
+func (g *SlowRuleGuard) Timings() map[string]RuleTiming {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	timings := make(map[string]RuleTiming, len(g.timings))
+	for id, t := range g.timings {
+		timings[id] = *t
+	}
+	return timings
+}
+
+// disabled reports whether the rule id has been disabled.
+func (g *SlowRuleGuard) disabled(id string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	t := g.timings[id]
+	return t != nil && t.Disabled
+}
+
+// observe records that the rule id took elapsed to match one input and
+// disables it once it has run over budget Strikes times in a row.
+func (g *SlowRuleGuard) observe(id string, elapsed time.Duration) {
+	g.mu.Lock()
+	if g.timings == nil {
+		g.timings = make(map[string]*RuleTiming)
+	}
+	t := g.timings[id]
+	if t == nil {
+		t = &RuleTiming{}
+		g.timings[id] = t
+	}
+	t.Calls++
+	t.Total += elapsed
+	t.Max = max(t.Max, elapsed)
+	if g.Budget <= 0 || elapsed <= g.Budget {
+		t.strikes = 0
+		g.mu.Unlock()
+		return
+	}
+	t.strikes++
+	strikes := g.Strikes
+	if strikes <= 0 {
+		strikes = DefaultSlowRuleStrikes
+	}
+	disable := !t.Disabled && t.strikes >= strikes && g.guards(id)
+	t.Disabled = t.Disabled || disable
+	timing := *t
+	g.mu.Unlock()
+
+	if disable && g.OnDisable != nil {
+		g.OnDisable(id, timing)
+	}
+}
+
+// guards reports whether the rule id may be disabled. g.mu must be held.
+func (g *SlowRuleGuard) guards(id string) bool {
+	if g.Rules == nil {
+		return true
+	}
+	if g.guarded == nil {
+		g.guarded = make(map[string]bool, len(g.Rules))
+		for _, r := range g.Rules {
+			g.guarded[r] = true
+		}
+	}
+	return g.guarded[id]
+}
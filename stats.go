@@ -0,0 +1,110 @@
+package secret
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Stats counts the secrets masked by a call or a whole stream.
+type Stats struct {
+	// ByRule counts masked secrets by rule ID. Detectors count under
+	// their own IDs, e.g. "high-entropy" or "credit-card".
+	ByRule map[string]int
+	// BySeverity counts masked secrets by the severity of the rule that
+	// matched them.
+	BySeverity map[Severity]int
+}
+
+// Total returns the number of masked secrets.
+func (s *Stats) Total() int {
+	total := 0
+	for _, n := range s.BySeverity {
+		total += n
+	}
+	return total
+}
+
+// String summarises the counts by severity, highest first, e.g.
+// "masked 3 CRITICAL, 1 HIGH".
+func (s *Stats) String() string {
+	if s.Total() == 0 {
+		return "masked 0 secrets"
+	}
+	levels := make([]Severity, 0, len(s.BySeverity))
+	for level, n := range s.BySeverity {
+		if n > 0 {
+			levels = append(levels, level)
+		}
+	}
+	sort.Slice(levels, func(i, j int) bool { return levels[i] > levels[j] })
+
+	parts := make([]string, len(levels))
+	for i, level := range levels {
+		parts[i] = fmt.Sprintf("%d %s", s.BySeverity[level], level)
+	}
+	return "masked " + strings.Join(parts, ", ")
+}
+
+// AtLeast returns the number of masked secrets whose severity is level or
+// higher.
+func (s *Stats) AtLeast(level Severity) int {
+	n := 0
+	for l, count := range s.BySeverity {
+		if l >= level {
+			n += count
+		}
+	}
+	return n
+}
+
+// SecretsFoundError reports that secrets of at least a severity were
+// masked, so that a CI step can fail once the masked log has been written.
+type SecretsFoundError struct {
+	// Severity is the threshold that was reached.
+	Severity Severity
+	// Count is the number of secrets at or above it.
+	Count int
+}
+
+func (e *SecretsFoundError) Error() string {
+	noun := "secrets"
+	if e.Count == 1 {
+		noun = "secret"
+	}
+	return fmt.Sprintf("found %d %s of severity %s or higher", e.Count, noun, e.Severity)
+}
+
+// add counts one secret masked by the rule or detector id.
+func (s *Stats) add(id string, level Severity) {
+	if s.ByRule == nil {
+		s.ByRule = make(map[string]int)
+		s.BySeverity = make(map[Severity]int)
+	}
+	s.ByRule[id]++
+	s.BySeverity[level]++
+}
+
+// merge adds the counts of other to s.
+func (s *Stats) merge(other *Stats) {
+	for id, n := range other.ByRule {
+		if s.ByRule == nil {
+			s.ByRule = make(map[string]int)
+		}
+		s.ByRule[id] += n
+	}
+	for level, n := range other.BySeverity {
+		if s.BySeverity == nil {
+			s.BySeverity = make(map[Severity]int)
+		}
+		s.BySeverity[level] += n
+	}
+}
+
+// MaskSecretsOnStringWithStats is like MaskSecretsOnStringWithOptions but
+// also returns how many secrets were masked.
+func MaskSecretsOnStringWithStats(input string, rules []Rule, opts MaskOptions) (string, Stats) {
+	var stats Stats
+	opts.stats = &stats
+	return MaskSecretsOnStringWithOptions(input, rules, opts), stats
+}
@@ -0,0 +1,645 @@
+package secret
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"regexp"
+	"strings"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/transform"
+)
+
+// MaskSecretsStream masks the buffered input line by line.
+// It is kept for existing callers; new code should use MaskSecretsReader.
+func MaskSecretsStream(input *bytes.Buffer) (io.Reader, error) {
+	return MaskSecretsReader(input), nil
+}
+
+// MaskSecretsReader returns a reader that yields the contents of r with
+// secrets masked. Input is scanned line by line and written through a pipe,
+// so at most one scanner buffer is read ahead of the consumer.
+func MaskSecretsReader(r io.Reader) io.Reader {
+	return MaskSecretsReaderWithOptions(r, StreamOptions{})
+}
+
+// DefaultBufferSize is the default capacity of the line scanner buffer.
+const DefaultBufferSize = 256 * 1024 // 256KB
+
+// StreamOptions configures the streaming masker.
+type StreamOptions struct {
+	// BufferSize is the scanner buffer capacity and therefore the longest
+	// line that is masked as a whole. Longer lines are masked in chunks
+	// without line context. Zero means DefaultBufferSize.
+	BufferSize int
+	// Registry supplies the rules. The current rules are read for every
+	// line, so changes made through the registry apply mid-stream. Nil means
+	// DefaultRegistry.
+	Registry *Registry
+	// Options controls how secrets are replaced.
+	Options MaskOptions
+	// Stats, when set, accumulates the counts of masked secrets over the
+	// whole stream. It is complete once the reader has returned EOF or an
+	// error, and must not be read before.
+	Stats *Stats
+	// Encoding is the character encoding of the input, such as
+	// unicode.UTF16 or charmap.ISO8859_1 from golang.org/x/text. The input
+	// is transcoded to UTF-8 for matching and the output is written back
+	// in the same encoding. Nil means the input is already UTF-8.
+	Encoding encoding.Encoding
+	// LinePrefix, when set, matches the leading part of a line that must
+	// never be masked, such as a timestamp and level. If it matches at the
+	// start of a line, that prefix is copied through and only the rest of
+	// the line is masked. Lines it does not match are masked whole.
+	LinePrefix *regexp.Regexp
+	// MaxLineLength, when positive, is the longest line masked as a whole,
+	// in bytes excluding the terminator, and replaces BufferSize. Longer
+	// lines are handled according to LongLines and never buffered whole.
+	MaxLineLength int
+	// LongLines decides what is emitted for a line longer than
+	// MaxLineLength.
+	LongLines LongLinePolicy
+	// JSONValues masks each line that is a JSON object or array value by
+	// value: keys, structure and formatting are kept, and replacements are
+	// escaped so the line stays valid JSON. Other lines are masked as
+	// plain text. ANSI and LineTimeout do not apply to JSON lines.
+	JSONValues bool
+	// OnFinding, when set, is called from the masking goroutine for each
+	// secret the rules mask, in stream order and before the line holding
+	// it is written. Line, Column, Start and End are counted from the
+	// beginning of the stream, or of its UTF-8 text when Encoding is set.
+	// Match is always empty, so the plaintext secret is never passed on.
+	// Overlapping matches that are masked as one are reported once, under
+	// the rule that masked them. Secrets found by detectors, and lines
+	// dropped or truncated because they exceed MaxLineLength, are not
+	// reported.
+	OnFinding func(Finding)
+	// FailOn, when above SeverityUnknown, makes the reader fail with a
+	// *SecretsFoundError instead of returning io.EOF if secrets of at least
+	// this severity were masked. The error is returned only after the
+	// whole masked stream has been read. Secrets found by detectors have
+	// no severity and do not count.
+	FailOn Severity
+	// Binary masks the input as raw bytes rather than lines, for pipes
+	// that mix text with binary data or control bytes. The input is read
+	// in blocks and each is masked together with the last maxSecretLen
+	// bytes of the one before, so no line structure is assumed and bytes
+	// outside secrets, including NUL and invalid UTF-8, pass through
+	// unchanged. Output lags input by up to that overlap, and more text is
+	// rescanned than in line mode. Private key blocks are held back from
+	// their BEGIN marker until their END marker, or until
+	// maxPEMBlockBytes, as in line mode. BufferSize, LinePrefix,
+	// MaxLineLength and JSONValues do not apply.
+	Binary bool
+}
+
+// MaskSecretsReaderWithOptions is like MaskSecretsReader but configured by opts.
+func MaskSecretsReaderWithOptions(r io.Reader, opts StreamOptions) io.Reader {
+	return maskReader(context.Background(), r, opts)
+}
+
+// MaskSecretsStreamContext is like MaskSecretsReader but stops when ctx is
+// done: the returned reader then fails with ctx.Err(), and no further lines
+// are read from r once the current read returns.
+func MaskSecretsStreamContext(ctx context.Context, r io.Reader) io.Reader {
+	return maskReader(ctx, r, StreamOptions{})
+}
+
+// maskReader masks r into a pipe from a new goroutine until r is exhausted
+// or ctx is done.
+func maskReader(ctx context.Context, r io.Reader, opts StreamOptions) io.Reader {
+	pr, pw := io.Pipe()
+
+	// Close the pipe as soon as ctx is done, so a consumer blocked in Read
+	// returns promptly even while r is blocked.
+	stop := context.AfterFunc(ctx, func() {
+		pw.CloseWithError(ctx.Err())
+	})
+	go func() {
+		defer stop()
+		// Closing with the error makes the consumer's Read fail instead of
+		// seeing a clean EOF on truncated, partially masked output.
+		pw.CloseWithError(maskEncoded(ctx, r, pw, opts))
+	}()
+	return pr
+}
+
+// maskEncoded runs maskLines on r transcoded from opts.Encoding to UTF-8 and
+// encodes the masked output back before writing it to w.
+func maskEncoded(ctx context.Context, r io.Reader, w io.Writer, opts StreamOptions) error {
+	if opts.Encoding == nil {
+		return maskLines(ctx, r, w, opts)
+	}
+	ew := transform.NewWriter(w, opts.Encoding.NewEncoder())
+	err := maskLines(ctx, transform.NewReader(r, opts.Encoding.NewDecoder()), ew, opts)
+	if closeErr := ew.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// maskLines scans r line by line and writes the masked lines to w. It returns
+// the first read or write error encountered, or a *SecretsFoundError when
+// opts.FailOn is reached. Line terminators are copied through unchanged, so
+// CRLF endings and a missing final newline survive.
+//
+// Private key blocks span several lines, so once a BEGIN marker is seen the
+// following lines are buffered until the matching END marker and the whole
+// block is masked at once.
+//
+// Lines longer than the buffer are masked in chunks. The last maxSecretLen
+// bytes of each chunk are carried over and masked together with the next
+// one, so a secret straddling a chunk boundary is still matched.
+//
+// ctx is checked before each line; maskLines returns ctx.Err() once it is
+// done. With opts.Binary, r is masked by maskWindows instead.
+func maskLines(ctx context.Context, r io.Reader, w io.Writer, opts StreamOptions) error {
+	scanner := bufio.NewScanner(r)
+	maxCapacity := opts.BufferSize
+	if maxCapacity <= 0 {
+		maxCapacity = DefaultBufferSize
+	}
+	if opts.MaxLineLength > 0 {
+		// Room for the longest allowed line and a CRLF terminator.
+		maxCapacity = opts.MaxLineLength + 2
+		if opts.LongLines == LongLineTruncate {
+			// Read past the cut so that a secret straddling it is
+			// masked before the line is truncated.
+			maxCapacity += maxSecretLen
+		}
+	}
+	// dropLong is set when over-long lines are not masked in windows.
+	dropLong := opts.MaxLineLength > 0 && opts.LongLines != LongLineWindows
+	buf := make([]byte, maxCapacity)
+	scanner.Buffer(buf, maxCapacity)
+
+	registry := opts.Registry
+	if registry == nil {
+		registry = DefaultRegistry
+	}
+	lw := &lineWriter{w: w, registry: registry, opts: opts.Options, prefix: opts.LinePrefix, json: opts.JSONValues, onFinding: opts.OnFinding, line: 1}
+	lw.opts.stats = opts.Stats
+	if opts.FailOn > SeverityUnknown && lw.opts.stats == nil {
+		lw.opts.stats = &Stats{}
+	}
+	if opts.Binary {
+		lw.prefix, lw.json = nil, false
+		if err := lw.maskWindows(ctx, r); err != nil {
+			return err
+		}
+		return lw.failOn(opts.FailOn)
+	}
+
+	// partial is set when the scanned token is a chunk of an over-long line
+	// rather than a complete line.
+	var partial bool
+	scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		advance, token, err := scanLinesWithEOL(data, atEOF)
+		partial = false
+		if token == nil && err == nil && len(data) >= maxCapacity {
+			partial = true
+			return len(data), data, nil
+		}
+		return advance, token, err
+	})
+
+	var block pemBlock
+	var carry string
+	var long longLine
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		line := scanner.Text()
+
+		if dropLong {
+			content, eol := splitEOL(line)
+			if long.active {
+				lw.advance(line)
+				long.length += len(content)
+				if !partial {
+					long.active = false
+					if err := lw.writeLongLineTail(long.length, eol, opts.LongLines); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+			if partial || len(content) > opts.MaxLineLength {
+				if lines := block.take(); lines != nil {
+					if err := lw.writeUnterminatedBlock(lines); err != nil {
+						return err
+					}
+				}
+				if err := lw.writeLongLineHead(content, opts.LongLines, opts.MaxLineLength); err != nil {
+					return err
+				}
+				lw.advance(line)
+				long = longLine{active: partial, length: len(content)}
+				if !partial {
+					if err := lw.writeLongLineTail(long.length, eol, opts.LongLines); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+		}
+
+		if partial {
+			if lines := block.take(); lines != nil {
+				if err := lw.writeUnterminatedBlock(lines); err != nil {
+					return err
+				}
+			}
+			var err error
+			if carry, err = lw.writeChunk(carry + line); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if carry != "" {
+			line = carry + line
+			carry = ""
+			if err := lw.writeMasked(line); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if taken, lines, complete := block.add(line); taken {
+			switch {
+			case lines == nil:
+			case complete:
+				if err := lw.writeMasked(strings.Join(lines, "")); err != nil {
+					return err
+				}
+			default:
+				if err := lw.writeUnterminatedBlock(lines); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		if err := lw.writeMasked(line); err != nil {
+			return err
+		}
+	}
+
+	if lines := block.take(); lines != nil {
+		if err := lw.writeUnterminatedBlock(lines); err != nil {
+			return err
+		}
+	}
+	if long.active {
+		if err := lw.writeLongLineTail(long.length, "", opts.LongLines); err != nil {
+			return err
+		}
+	}
+	if carry != "" {
+		if err := lw.writeMasked(carry); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return lw.failOn(opts.FailOn)
+}
+
+// failOn returns a *SecretsFoundError if secrets of at least level have
+// been masked, and nil when level is SeverityUnknown.
+func (lw *lineWriter) failOn(level Severity) error {
+	if level > SeverityUnknown {
+		if n := lw.opts.stats.AtLeast(level); n > 0 {
+			return &SecretsFoundError{Severity: level, Count: n}
+		}
+	}
+	return nil
+}
+
+// binaryBlockSize is the number of bytes read at a time in binary mode.
+const binaryBlockSize = 32 * 1024
+
+// maskWindows masks r as raw bytes for StreamOptions.Binary, carrying the
+// tail of each block over to the next like the chunks of an over-long line.
+// From the BEGIN marker of a private key block, output is held back until
+// its END marker arrives, as line mode does, because a key is longer than
+// the carry-over.
+func (lw *lineWriter) maskWindows(ctx context.Context, r io.Reader) error {
+	buf := make([]byte, binaryBlockSize)
+	var carry string
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		n, err := r.Read(buf)
+		if n > 0 {
+			var werr error
+			if carry, werr = lw.writeWindow(carry + string(buf[:n])); werr != nil {
+				return werr
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if carry == "" {
+		return nil
+	}
+	if pemBeginRegex.MatchString(carry) {
+		return lw.writeUnterminatedBlock(strings.SplitAfter(carry, "\n"))
+	}
+	return lw.writeMasked(carry)
+}
+
+// writeWindow masks and writes the part of text, read in binary mode, that
+// can no longer be affected by the bytes that follow it, and returns the
+// rest. Complete private key blocks are masked whole; an unterminated one
+// is returned from its BEGIN marker on, unless it has grown past
+// maxPEMBlockBytes, when it is written as a truncated block.
+func (lw *lineWriter) writeWindow(text string) (string, error) {
+	for {
+		begin := pemBeginRegex.FindStringIndex(text)
+		if begin == nil {
+			return lw.writeChunk(text)
+		}
+		end := pemEndRegex.FindStringIndex(text[begin[1]:])
+		if end != nil {
+			cut := begin[1] + end[1]
+			if err := lw.writeMasked(text[:cut]); err != nil {
+				return "", err
+			}
+			text = text[cut:]
+			continue
+		}
+		if begin[0] > 0 {
+			if err := lw.writeMasked(text[:begin[0]]); err != nil {
+				return "", err
+			}
+		}
+		if len(text)-begin[0] > maxPEMBlockBytes {
+			return "", lw.writeUnterminatedBlock(strings.SplitAfter(text[begin[0]:], "\n"))
+		}
+		return text[begin[0]:], nil
+	}
+}
+
+// maxSecretLen is the number of bytes carried over between the chunks of an
+// over-long line. It must be at least the length of the longest secret a
+// rule can match for such a secret to be found across a chunk boundary.
+const maxSecretLen = 1024
+
+// writeChunk masks and writes the part of an over-long line that can no
+// longer be affected by the bytes that follow it, and returns the unwritten
+// tail that must be prepended to the next chunk. The cut is moved back so
+// that it never falls inside a rule match.
+func (lw *lineWriter) writeChunk(text string) (string, error) {
+	cut := len(text) - maxSecretLen
+	if cut <= 0 {
+		return text, nil
+	}
+
+	rules, index := lw.registry.indexed()
+	spans := matchSpans(text, rules)
+	for moved := true; moved; {
+		moved = false
+		for _, sp := range spans {
+			if sp.start < cut && cut < sp.end {
+				cut = sp.start
+				moved = true
+			}
+		}
+	}
+	if cut <= 0 {
+		// A match longer than the carry-over window; cut through it rather
+		// than buffering without bound.
+		cut = len(text) - maxSecretLen
+	}
+
+	opts := lw.opts
+	opts.keywords = index
+	lw.report(text[:cut], 0)
+	maskedString := MaskSecretsOnStringWithOptions(text[:cut], rules, opts)
+	if _, err := lw.w.Write([]byte(maskedString)); err != nil {
+		return "", err
+	}
+	lw.advance(text[:cut])
+	return text[cut:], nil
+}
+
+// scanLinesWithEOL is a bufio.SplitFunc like bufio.ScanLines, except that
+// each token keeps its line terminator. The final token has no terminator
+// when the input does not end with a newline.
+func scanLinesWithEOL(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		return i + 1, data[:i+1], nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// splitEOL separates a scanned line into its content and its terminator,
+// which is "\r\n", "\n" or empty.
+func splitEOL(line string) (content, eol string) {
+	if strings.HasSuffix(line, "\r\n") {
+		return line[:len(line)-2], "\r\n"
+	}
+	if strings.HasSuffix(line, "\n") {
+		return line[:len(line)-1], "\n"
+	}
+	return line, ""
+}
+
+// maxPEMBlockLines bounds how many lines are buffered while waiting for the
+// END marker of a private key block. A 4096-bit RSA key is about 50 lines.
+const maxPEMBlockLines = 256
+
+// pemBlock buffers the lines of a private key block until its END marker
+// arrives, so that the block can be masked or scanned as one text.
+type pemBlock struct {
+	lines []string
+}
+
+// add offers the next line to the block and reports whether it was taken:
+// it continues an open block, or opens one with a BEGIN marker whose END
+// marker is not on the same line. Once the block is complete, or has
+// reached maxPEMBlockLines without an END marker, add also returns its
+// lines, with complete telling which.
+func (b *pemBlock) add(line string) (taken bool, lines []string, complete bool) {
+	if b.lines == nil {
+		if loc := pemBeginRegex.FindStringIndex(line); loc != nil && !pemEndRegex.MatchString(line[loc[1]:]) {
+			b.lines = []string{line}
+			return true, nil, false
+		}
+		return false, nil, false
+	}
+	b.lines = append(b.lines, line)
+	if pemEndRegex.MatchString(line) {
+		return true, b.take(), true
+	}
+	if len(b.lines) >= maxPEMBlockLines {
+		return true, b.take(), false
+	}
+	return true, nil, false
+}
+
+// take returns the lines of an open block, or nil, and resets it.
+func (b *pemBlock) take() []string {
+	lines := b.lines
+	b.lines = nil
+	return lines
+}
+
+// maxPEMBlockBytes bounds how much binary input is held back while waiting
+// for the END marker of a private key block.
+const maxPEMBlockBytes = 64 * 1024
+
+var (
+	pemBeginRegex = regexp.MustCompile(`(?i)-----\s*?BEGIN[ A-Z0-9_-]*?PRIVATE KEY( BLOCK)?\s*?-----`)
+	pemEndRegex   = regexp.MustCompile(`(?i)-----\s*?END[ A-Z0-9_-]*?PRIVATE KEY( BLOCK)?\s*?-----`)
+
+	// pemBodyLineRegex matches a line of base64 key material.
+	pemBodyLineRegex = regexp.MustCompile(`^[A-Za-z0-9+/=]+$`)
+)
+
+// lineWriter masks lines with the registry's current rules and writes them
+// to w.
+type lineWriter struct {
+	w         io.Writer
+	registry  *Registry
+	opts      MaskOptions
+	prefix    *regexp.Regexp
+	json      bool
+	onFinding func(Finding)
+
+	// line, column and offset locate the next input byte to be written:
+	// line is 1-based, column counts the bytes before it on its line and
+	// offset those before it in the stream.
+	line, column, offset int
+}
+
+// advance moves the stream position past text, which has been written.
+func (lw *lineWriter) advance(text string) {
+	lw.offset += len(text)
+	if i := strings.LastIndexByte(text, '\n'); i >= 0 {
+		lw.line += strings.Count(text, "\n")
+		lw.column = len(text) - i - 1
+	} else {
+		lw.column += len(text)
+	}
+}
+
+// report passes the secrets the rules find in text to lw.onFinding. text
+// starts skip bytes after the current stream position, on the same line.
+func (lw *lineWriter) report(text string, skip int) {
+	if lw.onFinding == nil || text == "" {
+		return
+	}
+	rules := lw.registry.rules()
+	for _, f := range mergeFindings(maskedFindings(text, rules, lw.opts), rules) {
+		if f.Line == 1 {
+			f.Column += lw.column + skip
+		}
+		f.Line += lw.line - 1
+		f.Start += lw.offset + skip
+		f.End += lw.offset + skip
+		f.Match = ""
+		lw.onFinding(f)
+	}
+}
+
+// mergeFindings joins the overlapping findings of rules, sorted as
+// FindSecrets sorts them, as mergeSpans joins the spans they are masked as,
+// so that each masked secret is reported once, under the rule that masked
+// it and at the start of the masked text.
+func mergeFindings(findings []Finding, rules []Rule) []Finding {
+	if len(findings) < 2 {
+		return findings
+	}
+	byID := make(map[string]*Rule, len(rules))
+	for i := range rules {
+		if _, ok := byID[rules[i].ID]; !ok {
+			byID[rules[i].ID] = &rules[i]
+		}
+	}
+	merged := findings[:1]
+	for _, f := range findings[1:] {
+		last := &merged[len(merged)-1]
+		if f.Start > last.End {
+			merged = append(merged, f)
+			continue
+		}
+		end := max(last.End, f.End)
+		if rulePriority(byID[f.RuleID]) > rulePriority(byID[last.RuleID]) {
+			f.Start, f.Line, f.Column = last.Start, last.Line, last.Column
+			*last = f
+		}
+		last.End = end
+	}
+	return merged
+}
+
+// writeMasked masks a line (or a buffered multi-line block) and writes it
+// followed by its original terminator. A leading match of lw.prefix is
+// written unmasked, and with lw.json set a JSON line is masked value by
+// value.
+func (lw *lineWriter) writeMasked(text string) error {
+	content, eol := splitEOL(text)
+	var prefix string
+	if lw.prefix != nil {
+		if loc := lw.prefix.FindStringIndex(content); loc != nil && loc[0] == 0 {
+			prefix, content = content[:loc[1]], content[loc[1]:]
+		}
+	}
+	lw.report(content, len(prefix))
+	defer lw.advance(text)
+	if lw.json {
+		rules, index := lw.registry.indexed()
+		opts := lw.opts
+		opts.keywords = index
+		if maskedString, ok := maskJSONLine(content, rules, opts); ok {
+			_, err := lw.w.Write([]byte(prefix + maskedString + eol))
+			return err
+		}
+	}
+	maskedString := lw.registry.Mask(content, lw.opts)
+	_, err := lw.w.Write([]byte(prefix + maskedString + eol))
+	return err
+}
+
+// writeUnterminatedBlock writes a private key block whose END marker never
+// arrived. The lines are masked individually and any line that looks like
+// key material is replaced outright, so a truncated key does not leak.
+func (lw *lineWriter) writeUnterminatedBlock(block []string) error {
+	masked := false
+	for _, line := range block {
+		content, eol := splitEOL(line)
+		if !pemBodyLineRegex.MatchString(content) {
+			if err := lw.writeMasked(line); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, err := lw.w.Write([]byte(lw.opts.replacement("private-key", content) + eol)); err != nil {
+			return err
+		}
+		lw.advance(line)
+		masked = true
+	}
+	if masked {
+		lw.opts.record("private-key", SeverityHigh)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package secret
+
+import (
+	"context"
+	"time"
+)
+
+// TimeoutPolicy decides what is emitted for an input whose masking exceeded
+// MaskOptions.LineTimeout.
+type TimeoutPolicy int
+
+const (
+	// TimeoutMaskLine replaces the whole input with the mask. It is the
+	// default because an input that could not be scanned may hold a secret.
+	TimeoutMaskLine TimeoutPolicy = iota
+	// TimeoutPassLine emits the input unchanged.
+	TimeoutPassLine
+)
+
+// TimeoutEvent describes an input whose masking timed out. It deliberately
+// carries no input text, which may contain secrets.
+type TimeoutEvent struct {
+	Length  int
+	Timeout time.Duration
+	Policy  TimeoutPolicy
+}
+
+// MaskSecretsOnStringContext masks input like MaskSecretsOnStringWithOptions
+// but gives up when ctx is done, returning ctx.Err(). Go regexes cannot be
+// interrupted, so an abandoned scan finishes in the background and its
+// result is discarded.
+func MaskSecretsOnStringContext(ctx context.Context, input string, rules []Rule, opts MaskOptions) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	// The scan counts into its own Stats, metrics buffer and repeat
+	// counter so that an abandoned scan cannot race with the caller
+	// reading opts.stats, nor report or number secrets whose masking was
+	// discarded.
+	stats, metrics, repeats := opts.stats, opts.Metrics, opts.Repeats
+	if stats != nil {
+		opts.stats = &Stats{}
+	}
+	if repeats != nil {
+		opts.Repeats = repeats.fork()
+	}
+	var buffer *metricsBuffer
+	if metrics != nil {
+		buffer = &metricsBuffer{}
+		opts.Metrics = buffer
+	}
+	done := make(chan string, 1)
+	go func() {
+		done <- maskSecrets(input, rules, opts)
+	}()
+
+	select {
+	case masked := <-done:
+		if stats != nil {
+			stats.merge(opts.stats)
+		}
+		if buffer != nil {
+			buffer.flush(metrics)
+		}
+		if repeats != nil {
+			opts.Repeats.commit()
+		}
+		return masked, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// maskWithTimeout masks input within opts.LineTimeout, falling back to
+// opts.TimeoutPolicy when the deadline is exceeded.
+func maskWithTimeout(input string, rules []Rule, opts MaskOptions) string {
+	ctx, cancel := context.WithTimeout(context.Background(), opts.LineTimeout)
+	defer cancel()
+
+	masked, err := MaskSecretsOnStringContext(ctx, input, rules, opts)
+	if err == nil {
+		return masked
+	}
+
+	if opts.OnTimeout != nil {
+		opts.OnTimeout(TimeoutEvent{Length: len(input), Timeout: opts.LineTimeout, Policy: opts.TimeoutPolicy})
+	}
+	if opts.TimeoutPolicy == TimeoutPassLine {
+		return input
+	}
+	return opts.mask(input)
+}
@@ -0,0 +1,47 @@
+package secret
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// tokenPrefix marks a pseudonymous token in masked output.
+const tokenPrefix = "SECRET_"
+
+// tokenHexLen is the number of hex digits of the HMAC kept in a token.
+const tokenHexLen = 12
+
+// hmacToken returns a stable pseudonym for secret. The same secret and key
+// always yield the same token, and the secret cannot be recovered from it
+// without the key.
+func hmacToken(secret string, key []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(secret))
+	return tokenPrefix + hex.EncodeToString(mac.Sum(nil))[:tokenHexLen]
+}
+
+// hashPrefix marks an unkeyed hash in masked output.
+const hashPrefix = "sha256:"
+
+// hashToken returns the first n hex digits of the SHA-256 of secret. Unlike
+// hmacToken it needs no key, so the same secret hashes identically across
+// systems; n is capped at the full digest length.
+func hashToken(secret string, n int) string {
+	sum := sha256.Sum256([]byte(secret))
+	digest := hex.EncodeToString(sum[:])
+	return hashPrefix + digest[:min(n, len(digest))]
+}
+
+// fingerprintHexLen is the number of hex digits kept in a fingerprint.
+const fingerprintHexLen = 6
+
+// fingerprint returns a fixed-width, self-describing stand-in for secret,
+// e.g. ‹secret:ab12cd›, made of the first hex digits of the SHA-256 of salt
+// followed by secret.
+func fingerprint(secret string, salt []byte) string {
+	h := sha256.New()
+	h.Write(salt)
+	h.Write([]byte(secret))
+	return "‹secret:" + hex.EncodeToString(h.Sum(nil))[:fingerprintHexLen] + "›"
+}
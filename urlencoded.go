@@ -0,0 +1,90 @@
+package secret
+
+import (
+	"regexp"
+	"strings"
+)
+
+// urlComponentRegex matches URL components, such as a query parameter or a
+// path, that contain at least one percent escape. The delimiters between
+// query parameters end a component, so each is decoded and matched on its
+// own, as in aws_secret_access_key=wJalrXUtnFEMI%2FK7MDENG.
+var urlComponentRegex = regexp.MustCompile(`[^\s"'<>?&#;]*%[0-9A-Fa-f]{2}[^\s"'<>?&#;]*`)
+
+// URLEncodedDetector finds secrets that were percent-encoded, as when they
+// are logged as part of a URL and a + in a base64 secret became %2B. Each
+// URL component holding an escape is decoded and matched against Rules; the
+// encoded text of every secret found is masked in place, leaving the rest of
+// the component readable.
+type URLEncodedDetector struct {
+	// Rules are matched against the decoded components. Nil means
+	// BuiltinRules.
+	Rules []Rule
+}
+
+// Mask replaces the encoded form of every secret found in a decoded URL
+// component of input.
+func (d URLEncodedDetector) Mask(input string, opts MaskOptions) string {
+	rules := d.Rules
+	if rules == nil {
+		rules = BuiltinRules
+	}
+	components := urlComponentRegex.FindAllStringIndex(input, -1)
+	if components == nil {
+		return input
+	}
+
+	var sb strings.Builder
+	last := 0
+	for _, c := range components {
+		decoded, offsets := percentDecode(input[c[0]:c[1]])
+		for _, sp := range ruleSpans(decoded, rules, opts) {
+			if sp.keep {
+				continue
+			}
+			start, end := c[0]+offsets[sp.start], c[0]+offsets[sp.end]
+			sb.WriteString(input[last:start])
+			sb.WriteString(opts.ruleReplacement(sp.rule, decoded[sp.start:sp.end]))
+			last = end
+		}
+	}
+	sb.WriteString(input[last:])
+	return sb.String()
+}
+
+// percentDecode decodes the percent escapes in s. Decoding shortens the
+// text, so it also returns, for each byte of the result, its offset in s,
+// with a final entry for the end of s. Malformed escapes and + are kept as
+// they are.
+func percentDecode(s string) (string, []int) {
+	var sb strings.Builder
+	offsets := make([]int, 0, len(s)+1)
+	for i := 0; i < len(s); {
+		offsets = append(offsets, i)
+		if s[i] == '%' && i+2 < len(s) && isHex(s[i+1]) && isHex(s[i+2]) {
+			sb.WriteByte(unhex(s[i+1])<<4 | unhex(s[i+2]))
+			i += 3
+			continue
+		}
+		sb.WriteByte(s[i])
+		i++
+	}
+	offsets = append(offsets, len(s))
+	return sb.String(), offsets
+}
+
+// isHex reports whether c is a hex digit.
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+// unhex returns the value of the hex digit c.
+func unhex(c byte) byte {
+	switch {
+	case c <= '9':
+		return c - '0'
+	case c <= 'F':
+		return c - 'A' + 10
+	}
+	return c - 'a' + 10
+}
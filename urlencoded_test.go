@@ -0,0 +1,62 @@
+package secret_test
+
+import (
+	"secret"
+	"testing"
+)
+
+func TestURLEncodedDetector(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opts  secret.MaskOptions
+		want  string
+	}{
+		{
+			name:  "aws secret in query string",
+			input: "GET /upload?aws_secret_access_key=wJalrXUtnFEMI%2FK7MDENG%2BbPxRfiCYEXAMPLEKEY&region=us-east-1 200",
+			want:  "GET /upload?aws_secret_access_key=******&region=us-east-1 200",
+		},
+		{
+			name:  "lowercase escapes and encoded key name",
+			input: "url=https://example.com/cb?aws%5Fsecret%5Faccess%5Fkey=wJalrXUtnFEMI%2fK7MDENG%2bbPxRfiCYEXAMPLEKEY",
+			want:  "url=https://example.com/cb?aws%5Fsecret%5Faccess%5Fkey=******",
+		},
+		{
+			name:  "token encoded as a whole",
+			input: "redirect?next=%2Fhome&t=%67hp_0123456789abcdefghijABCDEFGHIJ3mpbCX",
+			want:  "redirect?next=%2Fhome&t=******",
+		},
+		{
+			name:  "replacement of the decoded secret",
+			input: "GET /upload?aws_secret_access_key=wJalrXUtnFEMI%2FK7MDENG%2BbPxRfiCYEXAMPLEKEY",
+			opts:  secret.MaskOptions{RevealLast: 4},
+			want:  "GET /upload?aws_secret_access_key=******EKEY",
+		},
+		{
+			name:  "encoded text without secrets",
+			input: "GET /search?q=hello%20world%21&page=2",
+			want:  "GET /search?q=hello%20world%21&page=2",
+		},
+		{
+			name:  "malformed escape",
+			input: "GET /p?x=100%&y=%zz",
+			want:  "GET /p?x=100%&y=%zz",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Detectors = []secret.Detector{secret.URLEncodedDetector{}}
+			got := secret.MaskSecretsOnStringWithOptions(tt.input, secret.BuiltinRules, tt.opts)
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	input := "GET /upload?aws_secret_access_key=wJalrXUtnFEMI%2FK7MDENG%2BbPxRfiCYEXAMPLEKEY"
+	if got := secret.MaskSecretsOnString(input, secret.BuiltinRules); got != input {
+		t.Errorf("without the detector, got %q, want %q unchanged", got, input)
+	}
+}
@@ -0,0 +1,199 @@
+package secret
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp/syntax"
+	"strings"
+	"unicode"
+)
+
+// ValidateRules checks that every rule has a compiled regex, that each
+// rule with a SecretGroupName actually defines that named group (without
+// it the whole match would be masked instead of just the secret) and that
+// each Replacement template is valid. The returned error lists every
+// offending rule by ID.
+func ValidateRules(rules []Rule) error {
+	var errs []error
+	for _, rule := range rules {
+		if err := validateRule(rule); err != nil {
+			errs = append(errs, fmt.Errorf("rule %q: %w", rule.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validateRule checks a single rule for ValidateRules.
+func validateRule(rule Rule) error {
+	if rule.Regex == nil {
+		return errors.New("no regex")
+	}
+	if rule.SecretGroupName != "" && rule.Regex.SubexpIndex(rule.SecretGroupName) < 0 {
+		return fmt.Errorf("regex has no group named %q", rule.SecretGroupName)
+	}
+	if strings.Contains(rule.Replacement, "{{") {
+		t, err := parseReplacement(rule.Replacement)
+		if err != nil {
+			return fmt.Errorf("replacement: %w", err)
+		}
+		if err := t.Execute(io.Discard, replacementData{}); err != nil {
+			return fmt.Errorf("replacement: %w", err)
+		}
+	}
+	return nil
+}
+
+// LintRules reports likely mistakes in rules that do not stop them from
+// working. It flags a bare (?i) after the start of a regex: the flag only
+// applies from that point to the end of the enclosing group, so a prefix
+// before it stays case-sensitive, which is rarely intended. Such rules
+// should put (?i) first or scope the flag explicitly with (?i:...).
+//
+// It also flags keywords that can never occur inside a match of the rule's
+// regex. Such a keyword only lets the rule run when it happens to appear
+// elsewhere on the line, so secrets without that context are missed.
+func LintRules(rules []Rule) error {
+	var errs []error
+	for _, rule := range rules {
+		if rule.Regex == nil {
+			continue
+		}
+		if i := strings.Index(rule.Regex.String(), "(?i)"); i > 0 {
+			errs = append(errs, fmt.Errorf("rule %q: (?i) at offset %d leaves the pattern before it case-sensitive; move it to the start or use (?i:...)", rule.ID, i))
+		}
+		for _, keyword := range unmatchableKeywords(rule) {
+			errs = append(errs, fmt.Errorf("rule %q: keyword %q never occurs in a match of its regex", rule.ID, keyword))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// unmatchableKeywords returns the rule's keywords that cannot be a substring
+// of any string its regex matches. Keywords are folded the same way
+// hasKeyword folds them. Empty-width assertions such as \b are treated as
+// always satisfied, so the check never reports a keyword that can match.
+func unmatchableKeywords(rule Rule) []string {
+	re, err := syntax.Parse(rule.Regex.String(), syntax.Perl)
+	if err != nil {
+		return nil
+	}
+	prog, err := syntax.Compile(re.Simplify())
+	if err != nil {
+		return nil
+	}
+	fold := rule.foldKeywords || strings.Contains(rule.Regex.String(), "(?i")
+
+	var bad []string
+	for _, keyword := range rule.Keywords {
+		if keyword != "" && !progContains(prog, keyword, fold) {
+			bad = append(bad, keyword)
+		}
+	}
+	return bad
+}
+
+// progContains reports whether some string accepted by prog contains s. It
+// starts from every rune instruction reachable from the start, consumes s,
+// and checks that a match is still reachable afterwards.
+func progContains(prog *syntax.Prog, s string, fold bool) bool {
+	reach := make(map[uint32]bool)
+	var walk func(pc uint32)
+	walk = func(pc uint32) {
+		if reach[pc] {
+			return
+		}
+		reach[pc] = true
+		inst := &prog.Inst[pc]
+		switch inst.Op {
+		case syntax.InstMatch, syntax.InstFail:
+		default:
+			walk(inst.Out)
+			if inst.Op == syntax.InstAlt || inst.Op == syntax.InstAltMatch {
+				walk(inst.Arg)
+			}
+		}
+	}
+	walk(uint32(prog.Start))
+
+	cur := make(map[uint32]bool)
+	for pc := range reach {
+		cur[pc] = true
+	}
+	for _, r := range s {
+		next := make(map[uint32]bool)
+		for pc := range cur {
+			if instMatchRune(&prog.Inst[pc], r, fold) {
+				epsilonClosure(prog, prog.Inst[pc].Out, next)
+			}
+		}
+		if len(next) == 0 {
+			return false
+		}
+		cur = next
+	}
+	for pc := range cur {
+		if canReachMatch(prog, pc, make(map[uint32]bool)) {
+			return true
+		}
+	}
+	return false
+}
+
+// epsilonClosure adds pc and every instruction reachable from it without
+// consuming input to set.
+func epsilonClosure(prog *syntax.Prog, pc uint32, set map[uint32]bool) {
+	if set[pc] {
+		return
+	}
+	set[pc] = true
+	inst := &prog.Inst[pc]
+	switch inst.Op {
+	case syntax.InstAlt, syntax.InstAltMatch:
+		epsilonClosure(prog, inst.Out, set)
+		epsilonClosure(prog, inst.Arg, set)
+	case syntax.InstCapture, syntax.InstEmptyWidth, syntax.InstNop:
+		epsilonClosure(prog, inst.Out, set)
+	}
+}
+
+// canReachMatch reports whether a match instruction is reachable from pc.
+func canReachMatch(prog *syntax.Prog, pc uint32, seen map[uint32]bool) bool {
+	if seen[pc] {
+		return false
+	}
+	seen[pc] = true
+	inst := &prog.Inst[pc]
+	switch inst.Op {
+	case syntax.InstMatch:
+		return true
+	case syntax.InstFail:
+		return false
+	case syntax.InstAlt, syntax.InstAltMatch:
+		return canReachMatch(prog, inst.Out, seen) || canReachMatch(prog, inst.Arg, seen)
+	}
+	return canReachMatch(prog, inst.Out, seen)
+}
+
+// instMatchRune reports whether inst consumes r, or any case variant of r
+// when fold is set.
+func instMatchRune(inst *syntax.Inst, r rune, fold bool) bool {
+	switch inst.Op {
+	case syntax.InstRuneAny:
+		return true
+	case syntax.InstRuneAnyNotNL:
+		return r != '\n'
+	case syntax.InstRune, syntax.InstRune1:
+		if inst.MatchRune(r) {
+			return true
+		}
+		if fold {
+			for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+				if inst.MatchRune(f) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package secret_test
+
+import (
+	"regexp"
+	"secret"
+	"strings"
+	"testing"
+)
+
+func TestValidateBuiltinRules(t *testing.T) {
+	if err := secret.ValidateRules(secret.BuiltinRules); err != nil {
+		t.Error(err)
+	}
+	if err := secret.ValidateRules(secret.PIIRules); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestValidateRules(t *testing.T) {
+	rules := []secret.Rule{
+		{ID: "ok", Regex: regexp.MustCompile(`key=(?P<secret>\w+)`), SecretGroupName: "secret"},
+		{ID: "missing-group", Regex: regexp.MustCompile(`key=(\w+)`), SecretGroupName: "secret"},
+		{ID: "no-regex"},
+		{ID: "bad-template", Regex: regexp.MustCompile(`key`), Replacement: "[{{.Nmae}}]"},
+	}
+
+	err := secret.ValidateRules(rules)
+	if err == nil {
+		t.Fatal("ValidateRules succeeded, want errors")
+	}
+	for _, id := range []string{`"missing-group"`, `"no-regex"`, `"bad-template"`} {
+		if !strings.Contains(err.Error(), id) {
+			t.Errorf("error %q does not name rule %s", err, id)
+		}
+	}
+	if strings.Contains(err.Error(), `"ok"`) {
+		t.Errorf("error %q names the valid rule", err)
+	}
+}
+
+func TestLintBuiltinRules(t *testing.T) {
+	if err := secret.LintRules(secret.BuiltinRules); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestLintRulesMidPatternFlag(t *testing.T) {
+	rules := []secret.Rule{
+		{ID: "mid", Regex: regexp.MustCompile(`tok_(?i)[a-z]{8}`)},
+		{ID: "start", Regex: regexp.MustCompile(`(?i)tok_[a-z]{8}`)},
+		{ID: "scoped", Regex: regexp.MustCompile(`tok_(?i:[a-z]{8})`)},
+	}
+	err := secret.LintRules(rules)
+	if err == nil || !strings.Contains(err.Error(), `"mid"`) {
+		t.Fatalf("LintRules error = %v, want one naming rule mid", err)
+	}
+	if strings.Contains(err.Error(), `"start"`) || strings.Contains(err.Error(), `"scoped"`) {
+		t.Errorf("LintRules flagged a correct rule: %v", err)
+	}
+}
+
+func TestLintRulesKeywords(t *testing.T) {
+	rules := []secret.Rule{
+		{ID: "no-keywords", Regex: regexp.MustCompile(`tok_[0-9]{8}`)},
+		{ID: "prefix", Regex: regexp.MustCompile(`\btok_[0-9]{8}\b`), Keywords: []string{"tok_"}},
+		{ID: "folded", Regex: regexp.MustCompile(`(?i)tok_[0-9]{8}`), Keywords: []string{"TOK_"}},
+		{ID: "context", Regex: regexp.MustCompile(`tok_[0-9]{8}`), Keywords: []string{"token"}},
+		{ID: "case", Regex: regexp.MustCompile(`tok_[0-9]{8}`), Keywords: []string{"TOK_"}},
+	}
+	err := secret.LintRules(rules)
+	if err == nil {
+		t.Fatal("LintRules succeeded, want errors")
+	}
+	for _, id := range []string{`"context"`, `"case"`} {
+		if !strings.Contains(err.Error(), id) {
+			t.Errorf("error %q does not name rule %s", err, id)
+		}
+	}
+	for _, id := range []string{`"no-keywords"`, `"prefix"`, `"folded"`} {
+		if strings.Contains(err.Error(), id) {
+			t.Errorf("error %q names valid rule %s", err, id)
+		}
+	}
+}
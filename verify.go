@@ -0,0 +1,156 @@
+package secret
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Verifier checks whether a secret is a live credential, typically with a
+// lightweight authenticated call to the provider's API. Verification is
+// strictly opt-in: it sends the secret over the network and is subject to
+// the provider's rate limits.
+type Verifier interface {
+	// Verify reports whether secret is currently valid. An error means the
+	// answer is unknown, not that the secret is invalid.
+	Verify(ctx context.Context, secret string) (bool, error)
+}
+
+// VerifyFindings sets Verified on each finding whose rule has a verifier
+// in verifiers, keyed by rule ID, and whose secret the verifier confirms.
+// The findings must still hold their Match. Findings that could not be
+// verified are left unconfirmed and their errors are returned joined.
+// Verification never affects masking; it only raises confidence.
+func VerifyFindings(ctx context.Context, findings []Finding, verifiers map[string]Verifier) error {
+	var errs []error
+	for i := range findings {
+		v, ok := verifiers[findings[i].RuleID]
+		if !ok || findings[i].Match == "" {
+			continue
+		}
+		live, err := v.Verify(ctx, findings[i].Match)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("verifying %s finding at %d:%d: %w", findings[i].RuleID, findings[i].Line, findings[i].Column, err))
+			continue
+		}
+		findings[i].Verified = live
+	}
+	return errors.Join(errs...)
+}
+
+// DefaultVerifiers returns verifiers for the GitHub, Stripe and Slack rules
+// that call the providers' public APIs with client, or with
+// http.DefaultClient when client is nil.
+func DefaultVerifiers(client *http.Client) map[string]Verifier {
+	github := GitHubVerifier{Client: client}
+	return map[string]Verifier{
+		"github-pat":              github,
+		"github-fine-grained-pat": github,
+		"github-oauth":            github,
+		"stripe-secret-token":     StripeVerifier{Client: client},
+		"slack-access-token":      SlackVerifier{Client: client},
+		"slack-bot-token":         SlackVerifier{Client: client},
+	}
+}
+
+// GitHubVerifier verifies GitHub tokens by fetching the authenticated user.
+type GitHubVerifier struct {
+	Client *http.Client
+	// BaseURL defaults to https://api.github.com.
+	BaseURL string
+}
+
+// Verify implements Verifier.
+func (v GitHubVerifier) Verify(ctx context.Context, secret string) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL(v.BaseURL, "https://api.github.com")+"/user", nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Authorization", "Bearer "+secret)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	return statusVerify(v.Client, req)
+}
+
+// StripeVerifier verifies Stripe secret keys by fetching the balance.
+type StripeVerifier struct {
+	Client *http.Client
+	// BaseURL defaults to https://api.stripe.com.
+	BaseURL string
+}
+
+// Verify implements Verifier.
+func (v StripeVerifier) Verify(ctx context.Context, secret string) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL(v.BaseURL, "https://api.stripe.com")+"/v1/balance", nil)
+	if err != nil {
+		return false, err
+	}
+	req.SetBasicAuth(secret, "")
+	return statusVerify(v.Client, req)
+}
+
+// SlackVerifier verifies Slack tokens with the auth.test method.
+type SlackVerifier struct {
+	Client *http.Client
+	// BaseURL defaults to https://slack.com.
+	BaseURL string
+}
+
+// Verify implements Verifier.
+func (v SlackVerifier) Verify(ctx context.Context, secret string) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL(v.BaseURL, "https://slack.com")+"/api/auth.test", nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Authorization", "Bearer "+secret)
+	resp, err := httpClient(v.Client).Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	// Slack reports invalid tokens in the body of a 200 response.
+	var body struct {
+		OK bool `json:"ok"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return false, err
+	}
+	return body.OK, nil
+}
+
+// statusVerify sends req and maps 2xx to valid and 401/403 to invalid; any
+// other status is an error.
+func statusVerify(client *http.Client, req *http.Request) (bool, error) {
+	resp, err := httpClient(client).Do(req)
+	if err != nil {
+		return false, err
+	}
+	resp.Body.Close()
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return true, nil
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return false, nil
+	default:
+		return false, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+}
+
+func httpClient(client *http.Client) *http.Client {
+	if client == nil {
+		return http.DefaultClient
+	}
+	return client
+}
+
+func baseURL(url, fallback string) string {
+	if url == "" {
+		return fallback
+	}
+	return strings.TrimSuffix(url, "/")
+}
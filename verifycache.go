@@ -0,0 +1,114 @@
+package secret
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"sync"
+	"time"
+)
+
+// DefaultVerifyCacheSize is the number of results a CachedVerifier created
+// with a non-positive size keeps.
+const DefaultVerifyCacheSize = 1024
+
+// CachedVerifier remembers the answers of another Verifier, so that a token
+// appearing thousands of times in a log is checked with the provider once.
+// Results are kept in a least-recently-used cache keyed by the SHA-256 of
+// the secret; the secret itself is never stored. Errors are not cached. It
+// is safe for concurrent use.
+type CachedVerifier struct {
+	next Verifier
+	size int
+	ttl  time.Duration
+
+	mu      sync.Mutex
+	entries map[[sha256.Size]byte]*list.Element
+	// order holds *verifyResult values, most recently used first.
+	order *list.List
+}
+
+// verifyResult is a cached answer of a Verifier.
+type verifyResult struct {
+	key     [sha256.Size]byte
+	live    bool
+	expires time.Time
+}
+
+// NewCachedVerifier returns a verifier that caches up to size answers of
+// next, or DefaultVerifyCacheSize when size is not positive, each for ttl.
+// A zero ttl keeps answers until they are evicted.
+func NewCachedVerifier(next Verifier, size int, ttl time.Duration) *CachedVerifier {
+	if size <= 0 {
+		size = DefaultVerifyCacheSize
+	}
+	return &CachedVerifier{
+		next:    next,
+		size:    size,
+		ttl:     ttl,
+		entries: make(map[[sha256.Size]byte]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// CacheVerifiers wraps each of verifiers, keyed by rule ID as for
+// VerifyFindings, in its own CachedVerifier.
+func CacheVerifiers(verifiers map[string]Verifier, size int, ttl time.Duration) map[string]Verifier {
+	cached := make(map[string]Verifier, len(verifiers))
+	for id, v := range verifiers {
+		cached[id] = NewCachedVerifier(v, size, ttl)
+	}
+	return cached
+}
+
+// Verify implements Verifier, asking the wrapped verifier only when no
+// unexpired answer for secret is cached.
+func (c *CachedVerifier) Verify(ctx context.Context, secret string) (bool, error) {
+	key := sha256.Sum256([]byte(secret))
+	if live, ok := c.lookup(key); ok {
+		return live, nil
+	}
+	live, err := c.next.Verify(ctx, secret)
+	if err != nil {
+		return false, err
+	}
+	c.store(key, live)
+	return live, nil
+}
+
+// lookup returns the cached answer for key, if any has not expired.
+func (c *CachedVerifier) lookup(key [sha256.Size]byte) (bool, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return false, false
+	}
+	r := e.Value.(*verifyResult)
+	if c.ttl > 0 && time.Now().After(r.expires) {
+		c.order.Remove(e)
+		delete(c.entries, key)
+		return false, false
+	}
+	c.order.MoveToFront(e)
+	return r.live, true
+}
+
+// store caches live as the answer for key, evicting the least recently
+// used answer when the cache is full.
+func (c *CachedVerifier) store(key [sha256.Size]byte, live bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	r := &verifyResult{key: key, live: live, expires: time.Now().Add(c.ttl)}
+	if e, ok := c.entries[key]; ok {
+		e.Value = r
+		c.order.MoveToFront(e)
+		return
+	}
+	c.entries[key] = c.order.PushFront(r)
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*verifyResult).key)
+	}
+}
@@ -0,0 +1,39 @@
+//go:build !js
+
+package secret_test
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// TestWASMBuild checks that the library and cmd/secret-wasm compile for
+// js/wasm, and that the library does not depend on os/exec, which belongs
+// in the CLI.
+func TestWASMBuild(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds for js/wasm")
+	}
+	gotool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go tool not found")
+	}
+
+	out, err := exec.Command(gotool, "list", "-deps", ".").Output()
+	if err != nil {
+		t.Fatalf("go list: %v", err)
+	}
+	for _, dep := range strings.Fields(string(out)) {
+		if dep == "os/exec" {
+			t.Error("package secret depends on os/exec")
+		}
+	}
+
+	cmd := exec.Command(gotool, "build", "-o", os.DevNull, ".", "./cmd/secret-wasm")
+	cmd.Env = append(os.Environ(), "GOOS=js", "GOARCH=wasm")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Errorf("GOOS=js GOARCH=wasm go build: %v\n%s", err, out)
+	}
+}
@@ -0,0 +1,80 @@
+package secret
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+// WatchPatterns keeps registry in sync with a patterns.yaml file. It loads
+// the file, installs BuiltinRules plus the file's patterns in registry, and
+// then polls the file every interval until ctx is done, reloading it
+// whenever its contents change. The swap is atomic, so masking in progress
+// keeps the rules it started with.
+//
+// An error loading the file the first time, or an interval that is not
+// positive, is returned. Errors on later reloads are passed to onError, or
+// logged when onError is nil, and the previous rules stay in place.
+func WatchPatterns(ctx context.Context, registry *Registry, filename string, interval time.Duration, onError func(error)) error {
+	if onError == nil {
+		onError = func(err error) { log.Printf("reloading patterns: %v", err) }
+	}
+
+	if interval <= 0 {
+		return fmt.Errorf("watching %s: interval %v is not positive", filename, interval)
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	if err := installPatterns(registry, filename, data); err != nil {
+		return err
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			current, err := os.ReadFile(filename)
+			if err != nil {
+				onError(err)
+				continue
+			}
+			if bytes.Equal(current, data) {
+				continue
+			}
+			// Remember the contents even when they are invalid, so the
+			// same error is not reported on every tick.
+			data = current
+			if err := installPatterns(registry, filename, data); err != nil {
+				onError(err)
+			}
+		}
+	}()
+	return nil
+}
+
+// installPatterns replaces the rules in registry with BuiltinRules plus the
+// patterns in data, which was read from filename. On error the registry is
+// left unchanged.
+func installPatterns(registry *Registry, filename string, data []byte) error {
+	patterns, err := parsePatterns(filename, data)
+	if err != nil {
+		return err
+	}
+	rules, err := RulesWithPatterns(patterns)
+	if err != nil {
+		return err
+	}
+	registry.ReplaceRules(rules)
+	return nil
+}
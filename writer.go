@@ -0,0 +1,78 @@
+package secret
+
+import (
+	"bytes"
+	"errors"
+	"io"
+)
+
+// ErrWriterClosed is returned by MaskingWriter.Write after Close.
+var ErrWriterClosed = errors.New("secret: write to closed MaskingWriter")
+
+// MaskingWriter is an io.Writer that masks secrets in everything written
+// through it before passing it on. Input is buffered until a newline so
+// that a secret split across several Write calls is still matched; call
+// Close when done to write a trailing line without a newline.
+type MaskingWriter struct {
+	lw     *lineWriter
+	buf    []byte
+	closed bool
+}
+
+// NewMaskingWriter returns a MaskingWriter that masks secrets matched by
+// rules and writes the result to w.
+func NewMaskingWriter(w io.Writer, rules []Rule) *MaskingWriter {
+	return &MaskingWriter{lw: &lineWriter{w: w, registry: NewRegistry(rules)}}
+}
+
+// Write masks and writes every complete line in p, buffering any trailing
+// partial line. Lines longer than DefaultBufferSize are masked in chunks
+// as MaskSecretsReader does. It always reports len(p) bytes written, also
+// with an error from the underlying writer, because p has been buffered by
+// then and must not be written again.
+func (mw *MaskingWriter) Write(p []byte) (int, error) {
+	if mw.closed {
+		return 0, ErrWriterClosed
+	}
+	mw.buf = append(mw.buf, p...)
+	for {
+		i := bytes.IndexByte(mw.buf, '\n')
+		if i < 0 {
+			break
+		}
+		if err := mw.lw.writeMasked(string(mw.buf[:i+1])); err != nil {
+			return len(p), err
+		}
+		mw.buf = mw.buf[i+1:]
+	}
+	if len(mw.buf) >= DefaultBufferSize {
+		carry, err := mw.lw.writeChunk(string(mw.buf))
+		if err != nil {
+			return len(p), err
+		}
+		mw.buf = []byte(carry)
+	}
+	return len(p), nil
+}
+
+// Flush masks and writes the buffered partial line. A secret that is split
+// between the data before and after a Flush is not matched, so Flush should
+// only be called at a point where the output must be complete.
+func (mw *MaskingWriter) Flush() error {
+	if len(mw.buf) == 0 {
+		return nil
+	}
+	err := mw.lw.writeMasked(string(mw.buf))
+	mw.buf = nil
+	return err
+}
+
+// Close flushes the buffered partial line. It does not close the underlying
+// writer. Writes after Close fail with ErrWriterClosed.
+func (mw *MaskingWriter) Close() error {
+	if mw.closed {
+		return nil
+	}
+	mw.closed = true
+	return mw.Flush()
+}